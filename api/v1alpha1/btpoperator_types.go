@@ -17,11 +17,36 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const componentName = "btp-operator"
 
+const (
+	// Finalizer is added by btp-manager to every BtpOperator CR and removed once the module is deprovisioned.
+	Finalizer = "operator.kyma-project.io/btp-manager"
+
+	// ForceDeleteLabelKey set to "true" allows deprovisioning while ServiceInstances and ServiceBindings exist.
+	ForceDeleteLabelKey = "force-delete"
+
+	// AllowAdditionalCRAnnotation set to "true" allows creating a BtpOperator CR while another one exists,
+	// for example, during migrations.
+	AllowAdditionalCRAnnotation = "operator.kyma-project.io/allow-additional-cr"
+	// DeprovisioningDryRunAnnotation set to "true" makes btp-manager write a report of resources that deprovisioning would remove.
+	DeprovisioningDryRunAnnotation = "operator.kyma-project.io/deprovisioning-dry-run"
+	// RestoreBackupAnnotation set to "true" makes btp-manager recreate ServiceInstances and ServiceBindings from the backup
+	// taken during the soft delete, once the module is ready.
+	RestoreBackupAnnotation = "operator.kyma-project.io/restore-backup"
+	// DeleteCRDsAnnotation set to "true" makes btp-manager delete the ServiceInstance and ServiceBinding CRDs during deprovisioning.
+	// By default, CRDs are kept, so that remaining ServiceInstances and ServiceBindings are not deleted with them.
+	DeleteCRDsAnnotation = "operator.kyma-project.io/delete-crds"
+
+	// ModuleResourcesPatchLabelKey set to "true" on a ConfigMap in the module namespace makes btp-manager apply the patches
+	// from the ConfigMap to the module resources before applying them.
+	ModuleResourcesPatchLabelKey = "operator.kyma-project.io/module-resources-patch"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -41,7 +66,33 @@ type BtpOperator struct {
 }
 
 // BtpOperatorSpec defines the desired state of BtpOperator
-type BtpOperatorSpec struct{}
+type BtpOperatorSpec struct {
+	// DeletionPolicy defines what happens to ServiceInstances and ServiceBindings when the BtpOperator CR is deleted.
+	// +kubebuilder:validation:Enum=Block;Delete;Orphan
+	// +kubebuilder:default=Block
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Values overrides the values of the module chart and takes precedence over the chart values ConfigMap.
+	// If set, the module resources are rendered from the chart.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Values *apiextensionsv1.JSON `json:"values,omitempty"`
+}
+
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyBlock blocks deprovisioning while ServiceInstances or ServiceBindings exist, unless the force-delete label is set.
+	DeletionPolicyBlock DeletionPolicy = "Block"
+
+	// DeletionPolicyDelete deletes ServiceInstances and ServiceBindings in the hard delete mode and falls back to the soft delete mode.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	// DeletionPolicyOrphan removes module components but leaves ServiceInstances, ServiceBindings and their CRDs for a later reinstallation.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
 
 type State string
 
@@ -79,6 +130,105 @@ type Status struct {
 
 	// Conditions associated with CustomStatus.
 	Conditions []*metav1.Condition `json:"conditions,omitempty"`
+
+	// Restore reports the progress of recreating ServiceInstances and ServiceBindings from a backup.
+	Restore *RestoreStatus `json:"restore,omitempty"`
+
+	// Deprovisioning reports ServiceInstances and ServiceBindings remaining while the module is being deprovisioned.
+	Deprovisioning *DeprovisioningStatus `json:"deprovisioning,omitempty"`
+
+	// ValuesHash is the SHA-256 hash of the effective chart values the module resources were rendered with,
+	// without the credentials and the cluster ID from the required Secret. It is empty for pre-rendered module resources.
+	// +optional
+	ValuesHash string `json:"valuesHash,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+// DeprovisioningStatus defines ServiceInstances and ServiceBindings remaining while the module is being deprovisioned.
+type DeprovisioningStatus struct {
+	// Namespaces lists the number of remaining resources in namespaces that still have any.
+	Namespaces []NamespaceDeprovisioningStatus `json:"namespaces,omitempty"`
+
+	// StuckResources lists resources in deletion whose finalizers cannot be removed because of an error.
+	StuckResources []StuckResource `json:"stuckResources,omitempty"`
+
+	// BlockingResources lists up to 10 resources which block the deletion with the Block deletion policy.
+	BlockingResources []BlockingResource `json:"blockingResources,omitempty"`
+
+	// UnresolvedBindingSecrets lists up to 10 ServiceBindings orphaned in soft delete whose Secrets could not be deleted or kept.
+	UnresolvedBindingSecrets []UnresolvedBindingSecret `json:"unresolvedBindingSecrets,omitempty"`
+
+	// LastUpdateTime is the time of the last progress check.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// NamespaceDeprovisioningStatus defines the number of remaining resources in a namespace.
+type NamespaceDeprovisioningStatus struct {
+	Namespace        string `json:"namespace"`
+	ServiceInstances int    `json:"serviceInstances"`
+	ServiceBindings  int    `json:"serviceBindings"`
+	// LastError is the error of the last failed delete request in the namespace.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// StuckResource describes a resource in deletion with the last error reported by sap-btp-service-operator.
+type StuckResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// UnresolvedBindingSecret describes a ServiceBinding orphaned in soft delete whose Secret could not be deleted or kept.
+type UnresolvedBindingSecret struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// BlockingResource describes a ServiceInstance or ServiceBinding which blocks the deletion of the module.
+type BlockingResource struct {
+	Kind              string      `json:"kind"`
+	Namespace         string      `json:"namespace"`
+	Name              string      `json:"name"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	// OwnerReferences lists owners of the resource in the kind/name format.
+	// +optional
+	OwnerReferences []string `json:"ownerReferences,omitempty"`
+	// ServiceOfferingName is the service offering of a ServiceInstance.
+	// +optional
+	ServiceOfferingName string `json:"serviceOfferingName,omitempty"`
+	// ServicePlanName is the service plan of a ServiceInstance.
+	// +optional
+	ServicePlanName string `json:"servicePlanName,omitempty"`
+	// ServiceInstanceName is the ServiceInstance of a ServiceBinding.
+	// +optional
+	ServiceInstanceName string `json:"serviceInstanceName,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+// RestoreStatus defines the progress of recreating ServiceInstances and ServiceBindings from a backup.
+type RestoreStatus struct {
+	// Total is the number of resources in the backup.
+	Total int `json:"total"`
+
+	// Restored is the number of resources from the backup that exist in the cluster.
+	Restored int `json:"restored"`
+
+	// Failed lists resources that could not be recreated.
+	Failed []RestoreFailure `json:"failed,omitempty"`
+
+	// LastUpdateTime is the time of the last restore attempt.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// RestoreFailure describes a resource that could not be recreated from a backup.
+type RestoreFailure struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
 }
 
 func (s *Status) WithState(state State) Status {
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"github.com/kyma-project/btp-manager/internal/manifest"
+)
+
+const errorExitCode = 1
+
+// checksums writes the checksums file into every given directory with manifests, signed with the ed25519 private key
+// from the key file if it's set. The key file holds the base64-encoded 32-byte seed or 64-byte private key.
+func main() {
+	var keyFile string
+	flag.StringVar(&keyFile, "key-file", "", "File with the base64-encoded ed25519 private key to sign the checksums with.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-key-file file] dir...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(errorExitCode)
+	}
+
+	key, err := readPrivateKey(keyFile)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(errorExitCode)
+	}
+
+	for _, dir := range flag.Args() {
+		if err := writeChecksums(dir, key); err != nil {
+			fmt.Println(err)
+			os.Exit(errorExitCode)
+		}
+		fmt.Printf("%s written\n", filepath.Join(dir, manifest.ChecksumsFile))
+	}
+}
+
+func readPrivateKey(keyFile string) (ed25519.PrivateKey, error) {
+	if keyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("while reading the key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(key), nil
+	default:
+		return nil, fmt.Errorf("invalid private key: expected %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(key))
+	}
+}
+
+func writeChecksums(dir string, key ed25519.PrivateKey) error {
+	checksums, err := manifest.ComputeChecksums(fsutils.OSFS{}, dir, key)
+	if err != nil {
+		return fmt.Errorf("while computing checksums of %s directory: %w", dir, err)
+	}
+	data, err := json.MarshalIndent(checksums, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, manifest.ChecksumsFile), append(data, '\n'), 0o644)
+}
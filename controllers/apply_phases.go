@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const crdEstablishedCheckInterval = time.Second
+
+// applyPhase is a group of module resources, which are applied after the resources of the previous phases. Resources of
+// kinds which aren't listed in any phase are applied in the phase without kinds.
+type applyPhase struct {
+	name  string
+	kinds []string
+	// workloads is true for the phase with workloads, which must be available before the phases with afterWorkloads
+	workloads      bool
+	afterWorkloads bool
+}
+
+// applyPhases are ordered by the dependencies of the resources. CRDs are established before the custom resources are
+// applied, and webhook configurations are applied after the Deployments serving the webhooks are available.
+var applyPhases = []applyPhase{
+	{name: "namespaces and CRDs", kinds: []string{"Namespace", customResourceDefinitionKind}},
+	{name: "RBAC", kinds: []string{"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding"}},
+	{name: "configuration", kinds: []string{configMapKind, secretKind}},
+	{name: "services", kinds: []string{"Service"}},
+	{name: "other resources"},
+	{name: "workloads", kinds: []string{deploymentKind, "StatefulSet", "DaemonSet"}, workloads: true},
+	{name: "webhook configurations", kinds: []string{MutatingWebhookConfiguration, ValidatingWebhookConfiguration}, afterWorkloads: true},
+}
+
+// groupByApplyPhase returns the resources of every apply phase, keeping their order within the phase
+func groupByApplyPhase(us []*unstructured.Unstructured) [][]*unstructured.Unstructured {
+	phaseOfKind := make(map[string]int)
+	otherPhase := 0
+	for i, phase := range applyPhases {
+		if phase.kinds == nil {
+			otherPhase = i
+		}
+		for _, kind := range phase.kinds {
+			phaseOfKind[kind] = i
+		}
+	}
+
+	phases := make([][]*unstructured.Unstructured, len(applyPhases))
+	for _, u := range us {
+		phase, found := phaseOfKind[u.GetKind()]
+		if !found {
+			phase = otherPhase
+		}
+		phases[phase] = append(phases[phase], u)
+	}
+	return phases
+}
+
+// applyOrUpdateResources applies the resources phase by phase. It waits for the CRDs to be established after the phase
+// with CRDs, and for the workloads to be available before the webhook configurations are applied.
+func (r *BtpOperatorReconciler) applyOrUpdateResources(ctx context.Context, us []*unstructured.Unstructured) error {
+	logger := log.FromContext(ctx)
+
+	workloads := make([]*unstructured.Unstructured, 0)
+	for i, phase := range groupByApplyPhase(us) {
+		if len(phase) == 0 {
+			continue
+		}
+		if applyPhases[i].afterWorkloads && len(workloads) > 0 {
+			logger.Info(fmt.Sprintf("waiting for %d workloads to be available before %s phase", len(workloads), applyPhases[i].name))
+			if err := r.waitForResourcesReadiness(ctx, workloads); err != nil {
+				return fmt.Errorf("while waiting for workloads before %s phase: %w", applyPhases[i].name, err)
+			}
+		}
+
+		logger.Info(fmt.Sprintf("applying %d module resources in %s phase", len(phase), applyPhases[i].name))
+		for _, u := range phase {
+			if err := r.applyOrUpdateResource(ctx, u); err != nil {
+				return err
+			}
+		}
+		if applyPhases[i].workloads {
+			workloads = append(workloads, phase...)
+		}
+
+		if err := r.waitForCRDsEstablished(ctx, phase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForCRDsEstablished waits until the CRDs among the resources are established, so that their custom resources can be applied
+func (r *BtpOperatorReconciler) waitForCRDsEstablished(ctx context.Context, us []*unstructured.Unstructured) error {
+	for _, u := range us {
+		if u.GetKind() != customResourceDefinitionKind {
+			continue
+		}
+		err := wait.PollUntilContextTimeout(ctx, crdEstablishedCheckInterval, ReadyTimeout, true, func(ctx context.Context) (bool, error) {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			if err := r.Get(ctx, client.ObjectKey{Name: u.GetName()}, crd); err != nil {
+				return false, nil
+			}
+			return isCRDEstablished(crd), nil
+		})
+		if err != nil {
+			return fmt.Errorf("while waiting for CRD %s to be established: %w", u.GetName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestApplyOrUpdateResources(t *testing.T) {
+	// given
+	defaultReadyTimeout := ReadyTimeout
+	ReadyTimeout = time.Second * 5
+	defer func() { ReadyTimeout = defaultReadyTimeout }()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	resources, err := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), scheme, nil, nil).
+		createUnstructuredObjectsFromManifestsDir("../module-resources/apply")
+	require.NoError(t, err)
+
+	applied := make([]string, 0)
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			u := obj.(*unstructured.Unstructured)
+			applied = append(applied, u.GetKind())
+			if u.GetKind() == MutatingWebhookConfiguration || u.GetKind() == ValidatingWebhookConfiguration {
+				deployments := &appsv1.DeploymentList{}
+				require.NoError(t, c.List(ctx, deployments))
+				require.Len(t, deployments.Items, 1)
+				assert.NotEmpty(t, deployments.Items[0].Status.Conditions, "%s applied before the Deployment is available", u.GetKind())
+			}
+			// the fake client doesn't support server-side apply, so the objects are created with the status set by the API server
+			created := u.DeepCopy()
+			switch u.GetKind() {
+			case customResourceDefinitionKind:
+				require.NoError(t, unstructured.SetNestedSlice(created.Object, []interface{}{
+					map[string]interface{}{"type": string(apiextensionsv1.Established), "status": "True"},
+				}, "status", "conditions"))
+			case deploymentKind:
+				go func() {
+					time.Sleep(time.Millisecond * 100)
+					deployment := &appsv1.Deployment{}
+					if err := c.Get(context.Background(), client.ObjectKeyFromObject(u), deployment); err != nil {
+						return
+					}
+					deployment.Status.Conditions = []appsv1.DeploymentCondition{
+						{Type: deploymentProgressingConditionType, Status: "True"},
+						{Type: deploymentAvailableConditionType, Status: "True"},
+					}
+					_ = c.Status().Update(context.Background(), deployment)
+				}()
+			}
+			return c.Create(ctx, created)
+		},
+	}).WithStatusSubresource(&appsv1.Deployment{}).Build(), scheme, nil, nil)
+
+	// when
+	err = reconciler.applyOrUpdateResources(context.Background(), resources)
+
+	// then
+	require.NoError(t, err)
+	assert.Len(t, applied, len(resources))
+	assert.Equal(t, sortedByPhase(applied), applied)
+	assert.Equal(t, []string{customResourceDefinitionKind, customResourceDefinitionKind}, applied[:2])
+	assert.Equal(t, []string{MutatingWebhookConfiguration, ValidatingWebhookConfiguration}, applied[len(applied)-2:])
+}
+
+// sortedByPhase returns the kinds in the order of apply phases, keeping the order of kinds within each phase
+func sortedByPhase(kinds []string) []string {
+	us := make([]*unstructured.Unstructured, 0, len(kinds))
+	for _, kind := range kinds {
+		u := &unstructured.Unstructured{}
+		u.SetKind(kind)
+		us = append(us, u)
+	}
+	sorted := make([]string, 0, len(kinds))
+	for _, phase := range groupByApplyPhase(us) {
+		for _, u := range phase {
+			sorted = append(sorted, u.GetKind())
+		}
+	}
+	return sorted
+}
+
+func TestWaitForResourcesReadinessDeadline(t *testing.T) {
+	// given
+	defaultReadyTimeout := ReadyTimeout
+	ReadyTimeout = time.Second * 10
+	defer func() { ReadyTimeout = defaultReadyTimeout }()
+	deployment := &unstructured.Unstructured{}
+	deployment.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(deploymentKind))
+	deployment.SetName(DeploymentName)
+	deployment.SetNamespace(ChartNamespace)
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(deployment).Build(), clientgoscheme.Scheme, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	// when
+	start := time.Now()
+	err := reconciler.waitForResourcesReadiness(ctx, []*unstructured.Unstructured{deployment})
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "readiness timeout reached")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const bindingSecretsUnresolvedEventReason = "BindingSecretsUnresolved"
+
+// bindingSecretName returns the name of the Secret sap-btp-service-operator creates for the ServiceBinding.
+// The name is taken from spec.secretName and defaults to the ServiceBinding name. The secretTemplate can only customize
+// labels, annotations and data of the Secret, so it does not affect the name.
+func (r *BtpOperatorReconciler) bindingSecretName(binding unstructured.Unstructured) (string, error) {
+	secretName, found, err := unstructured.NestedString(binding.Object, "spec", "secretName")
+	if err != nil {
+		return "", fmt.Errorf("invalid spec.secretName: %w", err)
+	}
+	if !found || secretName == "" {
+		return binding.GetName(), nil
+	}
+	return secretName, nil
+}
+
+// getBindingSecret returns the Secret of the ServiceBinding if it exists and is controlled by the ServiceBinding, otherwise the returned message explains
+// why the Secret could not be resolved. The Secret is read as unstructured to bypass the cache, which only holds Secrets managed by btp-manager.
+func (r *BtpOperatorReconciler) getBindingSecret(ctx context.Context, binding unstructured.Unstructured) (*unstructured.Unstructured, string, error) {
+	secretName, err := r.bindingSecretName(binding)
+	if err != nil {
+		return nil, err.Error(), nil
+	}
+	secret := &unstructured.Unstructured{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(secretKind))
+	if err := r.Get(ctx, client.ObjectKey{Namespace: binding.GetNamespace(), Name: secretName}, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, fmt.Sprintf("secret %s not found", secretName), nil
+		}
+		return nil, "", err
+	}
+	if !metav1.IsControlledBy(secret, &binding) {
+		return nil, fmt.Sprintf("secret %s is not controlled by the binding", secretName), nil
+	}
+	return secret, "", nil
+}
+
+// handleBindingSecret deletes the Secret of the orphaned ServiceBinding or, if SoftDeleteKeepBindingSecrets is set, detaches it from the ServiceBinding
+// so that the garbage collector doesn't remove it and workloads can still use the credentials.
+// Secrets which could not be resolved are left untouched and the returned message explains why.
+func (r *BtpOperatorReconciler) handleBindingSecret(ctx context.Context, binding unstructured.Unstructured) (string, error) {
+	logger := log.FromContext(ctx)
+
+	secret, message, err := r.getBindingSecret(ctx, binding)
+	if err != nil || secret == nil {
+		return message, err
+	}
+
+	if !SoftDeleteKeepBindingSecrets {
+		if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		logger.Info("deleted secret of orphaned binding", "namespace", secret.GetNamespace(), "name", secret.GetName())
+		return "", nil
+	}
+
+	ownerReferences := make([]metav1.OwnerReference, 0)
+	for _, ownerReference := range secret.GetOwnerReferences() {
+		if ownerReference.UID != binding.GetUID() {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	secret.SetOwnerReferences(ownerReferences)
+	annotations := secret.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[forceOrphanedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	secret.SetAnnotations(annotations)
+	if err := r.Update(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	logger.Info("kept secret of orphaned binding", "namespace", secret.GetNamespace(), "name", secret.GetName())
+	return "", nil
+}
+
+// reportUnresolvedBindingSecrets stores Secrets of orphaned ServiceBindings that could not be deleted or kept in the deprovisioning status
+// and warns about them in an Event, both lists are bounded
+func (r *BtpOperatorReconciler) reportUnresolvedBindingSecrets(ctx context.Context, cr *v1alpha1.BtpOperator, unresolved []v1alpha1.UnresolvedBindingSecret) {
+	logger := log.FromContext(ctx)
+	if len(unresolved) == 0 {
+		return
+	}
+
+	listed := unresolved
+	if len(listed) > maxReportedResources {
+		listed = listed[:maxReportedResources]
+	}
+	described := make([]string, 0, len(listed))
+	for _, binding := range listed {
+		described = append(described, fmt.Sprintf("%s/%s (%s)", binding.Namespace, binding.Name, binding.Message))
+	}
+	message := fmt.Sprintf("secrets of %d orphaned Service Binding(s) could not be resolved: %s", len(unresolved), strings.Join(described, ", "))
+	if len(unresolved) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(unresolved)-len(listed))
+	}
+	logger.Info(message)
+	r.recorder.Event(cr, corev1.EventTypeWarning, bindingSecretsUnresolvedEventReason, message)
+
+	progress, err := r.collectDeprovisioningProgress(ctx, nil)
+	if err != nil {
+		logger.Error(err, "while collecting deprovisioning progress")
+		return
+	}
+	progress.UnresolvedBindingSecrets = listed
+	r.storeDeprovisioningProgress(ctx, cr, progress)
+}
+
+// mergeUnresolvedBindingSecrets adds the ServiceBindings to the previously reported ones, so that they are kept in the deprovisioning status
+// when the progress is updated. A ServiceBinding reported again is updated in place, and the list is bounded.
+func mergeUnresolvedBindingSecrets(previous, added []v1alpha1.UnresolvedBindingSecret) []v1alpha1.UnresolvedBindingSecret {
+	merged := slices.Clone(previous)
+	for _, binding := range added {
+		index := slices.IndexFunc(merged, func(b v1alpha1.UnresolvedBindingSecret) bool {
+			return b.Namespace == binding.Namespace && b.Name == binding.Name
+		})
+		if index >= 0 {
+			merged[index] = binding
+			continue
+		}
+		if len(merged) < maxReportedResources {
+			merged = append(merged, binding)
+		}
+	}
+	return merged
+}
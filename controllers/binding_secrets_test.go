@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testBindingSecretNamespace = "test-namespace"
+	testBindingSecretName      = "test-binding-secret"
+)
+
+func TestBtpOperatorReconciler_bindingSecretName(t *testing.T) {
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+	t.Run("should default to the binding name", func(t *testing.T) {
+		// when
+		name, err := reconciler.bindingSecretName(newTestBinding(""))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, bindingName, name)
+	})
+
+	t.Run("should use spec.secretName", func(t *testing.T) {
+		// when
+		name, err := reconciler.bindingSecretName(newTestBinding(testBindingSecretName))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, testBindingSecretName, name)
+	})
+}
+
+func TestBtpOperatorReconciler_handleBindingSecret(t *testing.T) {
+	defer func(keep bool) { SoftDeleteKeepBindingSecrets = keep }(SoftDeleteKeepBindingSecrets)
+	binding := newTestBinding(testBindingSecretName)
+	otherOwner := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "other", UID: types.UID("other-uid")}
+
+	t.Run("should delete the secret controlled by the binding", func(t *testing.T) {
+		// given
+		SoftDeleteKeepBindingSecrets = false
+		k8sClient := fake.NewClientBuilder().WithObjects(newTestBindingSecret(binding)).Build()
+		reconciler := NewBtpOperatorReconciler(k8sClient, clientgoscheme.Scheme, nil, nil)
+
+		// when
+		message, err := reconciler.handleBindingSecret(context.Background(), binding)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, message)
+		err = k8sClient.Get(context.Background(), client.ObjectKey{Namespace: testBindingSecretNamespace, Name: testBindingSecretName}, &corev1.Secret{})
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("should keep the secret and detach it from the binding", func(t *testing.T) {
+		// given
+		SoftDeleteKeepBindingSecrets = true
+		k8sClient := fake.NewClientBuilder().WithObjects(newTestBindingSecret(binding, otherOwner)).Build()
+		reconciler := NewBtpOperatorReconciler(k8sClient, clientgoscheme.Scheme, nil, nil)
+
+		// when
+		message, err := reconciler.handleBindingSecret(context.Background(), binding)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, message)
+		secret := &corev1.Secret{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: testBindingSecretNamespace, Name: testBindingSecretName}, secret))
+		assert.Equal(t, []metav1.OwnerReference{otherOwner}, secret.OwnerReferences)
+		assert.Contains(t, secret.Annotations, forceOrphanedAnnotation)
+	})
+
+	t.Run("should report a missing secret", func(t *testing.T) {
+		// given
+		SoftDeleteKeepBindingSecrets = false
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+		// when
+		message, err := reconciler.handleBindingSecret(context.Background(), binding)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "secret test-binding-secret not found", message)
+	})
+
+	t.Run("should not delete a secret which is not controlled by the binding", func(t *testing.T) {
+		// given
+		SoftDeleteKeepBindingSecrets = false
+		secret := newTestBindingSecret(binding)
+		secret.OwnerReferences = []metav1.OwnerReference{otherOwner}
+		k8sClient := fake.NewClientBuilder().WithObjects(secret).Build()
+		reconciler := NewBtpOperatorReconciler(k8sClient, clientgoscheme.Scheme, nil, nil)
+
+		// when
+		message, err := reconciler.handleBindingSecret(context.Background(), binding)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "secret test-binding-secret is not controlled by the binding", message)
+		assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: testBindingSecretNamespace, Name: testBindingSecretName}, &corev1.Secret{}))
+	})
+}
+
+func TestBtpOperatorReconciler_reportUnresolvedBindingSecrets(t *testing.T) {
+	// given
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	cr := &v1alpha1.BtpOperator{ObjectMeta: metav1.ObjectMeta{Name: btpOperatorName, Namespace: ChartNamespace}}
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).WithStatusSubresource(cr).Build(), scheme, nil, nil)
+	recorder := record.NewFakeRecorder(10)
+	reconciler.recorder = recorder
+	unresolved := []v1alpha1.UnresolvedBindingSecret{{Namespace: "ns1", Name: "binding", Message: "secret not found"}}
+
+	// when
+	reconciler.reportUnresolvedBindingSecrets(context.Background(), cr, unresolved)
+	reconciler.storeDeprovisioningProgress(context.Background(), cr, &v1alpha1.DeprovisioningStatus{})
+
+	// then
+	assert.Len(t, recorder.Events, 1)
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(cr), cr))
+	require.NotNil(t, cr.Status.Deprovisioning)
+	assert.Equal(t, unresolved, cr.Status.Deprovisioning.UnresolvedBindingSecrets, "the unresolved Secrets are kept after the progress update")
+}
+
+func TestMergeUnresolvedBindingSecrets(t *testing.T) {
+	newBinding := func(name, message string) v1alpha1.UnresolvedBindingSecret {
+		return v1alpha1.UnresolvedBindingSecret{Namespace: "ns1", Name: name, Message: message}
+	}
+
+	t.Run("should update the binding reported again", func(t *testing.T) {
+		// when
+		merged := mergeUnresolvedBindingSecrets(
+			[]v1alpha1.UnresolvedBindingSecret{newBinding("b1", "old"), newBinding("b2", "old")},
+			[]v1alpha1.UnresolvedBindingSecret{newBinding("b2", "new"), newBinding("b3", "new")},
+		)
+
+		// then
+		assert.Equal(t, []v1alpha1.UnresolvedBindingSecret{newBinding("b1", "old"), newBinding("b2", "new"), newBinding("b3", "new")}, merged)
+	})
+
+	t.Run("should bound the list", func(t *testing.T) {
+		// given
+		added := make([]v1alpha1.UnresolvedBindingSecret, 0)
+		for i := 0; i < maxReportedResources+1; i++ {
+			added = append(added, newBinding(fmt.Sprintf("b%d", i), "new"))
+		}
+
+		// when
+		merged := mergeUnresolvedBindingSecrets(nil, added)
+
+		// then
+		assert.Equal(t, added[:maxReportedResources], merged)
+	})
+}
+
+func newTestBinding(secretName string) unstructured.Unstructured {
+	binding := unstructured.Unstructured{}
+	binding.SetGroupVersionKind(bindingGvk)
+	binding.SetNamespace(testBindingSecretNamespace)
+	binding.SetName(bindingName)
+	binding.SetUID(types.UID("binding-uid"))
+	if secretName != "" {
+		_ = unstructured.SetNestedField(binding.Object, secretName, "spec", "secretName")
+	}
+	return binding
+}
+
+func newTestBindingSecret(binding unstructured.Unstructured, ownerReferences ...metav1.OwnerReference) *corev1.Secret {
+	secretName, _, _ := unstructured.NestedString(binding.Object, "spec", "secretName")
+	isController := true
+	controllerReference := metav1.OwnerReference{
+		APIVersion: binding.GetAPIVersion(),
+		Kind:       binding.GetKind(),
+		Name:       binding.GetName(),
+		UID:        binding.GetUID(),
+		Controller: &isController,
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       binding.GetNamespace(),
+			Name:            secretName,
+			OwnerReferences: append([]metav1.OwnerReference{controllerReference}, ownerReferences...),
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const deletionBlockedEventReason = "DeletionBlocked"
+
+// listBlockingResources returns all ServiceInstances and ServiceBindings, which block the deletion with the Block deletion policy
+func (r *BtpOperatorReconciler) listBlockingResources(ctx context.Context) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
+	instances, err := r.listResourcesInAllNamespaces(ctx, r.serviceInstanceGvk())
+	if err != nil {
+		return nil, nil, err
+	}
+	bindings, err := r.listResourcesInAllNamespaces(ctx, r.serviceBindingGvk())
+	if err != nil {
+		return nil, nil, err
+	}
+	return instances, bindings, nil
+}
+
+// blockingResourcesMessage returns the condition message with the numbers of blocking resources and a bounded list of them.
+// The message doesn't contain ages, so it changes only when the set of blocking resources changes.
+func (r *BtpOperatorReconciler) blockingResourcesMessage(instances, bindings []unstructured.Unstructured) string {
+	all := slices.Concat(instances, bindings)
+	listed := make([]string, 0, maxReportedResources)
+	for _, item := range all {
+		if len(listed) == maxReportedResources {
+			break
+		}
+		listed = append(listed, fmt.Sprintf("%s %s/%s", item.GetKind(), item.GetNamespace(), item.GetName()))
+	}
+
+	message := fmt.Sprintf("All service instances and bindings must be removed: %d instance(s) and %d binding(s): %s",
+		len(instances), len(bindings), strings.Join(listed, ", "))
+	if len(all) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(all)-len(listed))
+	}
+	return message
+}
+
+func (r *BtpOperatorReconciler) toBlockingResource(item unstructured.Unstructured) v1alpha1.BlockingResource {
+	resource := v1alpha1.BlockingResource{
+		Kind:              item.GetKind(),
+		Namespace:         item.GetNamespace(),
+		Name:              item.GetName(),
+		CreationTimestamp: item.GetCreationTimestamp(),
+	}
+	for _, ownerReference := range item.GetOwnerReferences() {
+		resource.OwnerReferences = append(resource.OwnerReferences, fmt.Sprintf("%s/%s", ownerReference.Kind, ownerReference.Name))
+	}
+	if item.GetKind() == btpOperatorServiceBinding {
+		resource.ServiceInstanceName, _, _ = unstructured.NestedString(item.Object, "spec", "serviceInstanceName")
+	} else {
+		resource.ServiceOfferingName, _, _ = unstructured.NestedString(item.Object, "spec", "serviceOfferingName")
+		resource.ServicePlanName, _, _ = unstructured.NestedString(item.Object, "spec", "servicePlanName")
+	}
+	return resource
+}
+
+// describeBlockingResource returns details which help to find out who created the resource and why it still exists
+func (r *BtpOperatorReconciler) describeBlockingResource(resource v1alpha1.BlockingResource, now time.Time) string {
+	details := []string{fmt.Sprintf("age: %s", duration.HumanDuration(now.Sub(resource.CreationTimestamp.Time)))}
+	if len(resource.OwnerReferences) > 0 {
+		details = append(details, fmt.Sprintf("owners: %s", strings.Join(resource.OwnerReferences, ", ")))
+	}
+	if resource.ServiceOfferingName != "" {
+		details = append(details, fmt.Sprintf("offering: %s", resource.ServiceOfferingName))
+	}
+	if resource.ServicePlanName != "" {
+		details = append(details, fmt.Sprintf("plan: %s", resource.ServicePlanName))
+	}
+	if resource.ServiceInstanceName != "" {
+		details = append(details, fmt.Sprintf("instance: %s", resource.ServiceInstanceName))
+	}
+	return fmt.Sprintf("%s %s/%s (%s)", resource.Kind, resource.Namespace, resource.Name, strings.Join(details, "; "))
+}
+
+// reportBlockingResources stores a bounded list of the blocking resources in the deprovisioning status and emits a Warning Event with their details.
+// Errors are only logged because the report is informational.
+func (r *BtpOperatorReconciler) reportBlockingResources(ctx context.Context, cr *v1alpha1.BtpOperator, instances, bindings []unstructured.Unstructured) {
+	logger := log.FromContext(ctx)
+
+	progress, err := r.collectDeprovisioningProgress(ctx, nil)
+	if err != nil {
+		logger.Error(err, "while collecting deprovisioning progress")
+		return
+	}
+	described := make([]string, 0, maxReportedResources)
+	now := time.Now()
+	for _, item := range slices.Concat(instances, bindings) {
+		if len(progress.BlockingResources) == maxReportedResources {
+			break
+		}
+		resource := r.toBlockingResource(item)
+		progress.BlockingResources = append(progress.BlockingResources, resource)
+		described = append(described, r.describeBlockingResource(resource, now))
+	}
+	r.storeDeprovisioningProgress(ctx, cr, progress)
+
+	message := fmt.Sprintf("deletion is blocked by %d Service Instance(s) and %d Service Binding(s): %s", len(instances), len(bindings), strings.Join(described, ", "))
+	if total := len(instances) + len(bindings); total > len(described) {
+		message += fmt.Sprintf(" and %d more", total-len(described))
+	}
+	logger.Info(message)
+	r.recorder.Event(cr, corev1.EventTypeWarning, deletionBlockedEventReason, message)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBtpOperatorReconciler_blockingResourcesMessage(t *testing.T) {
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+	newItems := func(gvk schema.GroupVersionKind, count int) []unstructured.Unstructured {
+		items := make([]unstructured.Unstructured, 0, count)
+		for i := 0; i < count; i++ {
+			item := unstructured.Unstructured{}
+			item.SetGroupVersionKind(gvk)
+			item.SetNamespace("test-namespace")
+			item.SetName(fmt.Sprintf("%s-%d", gvk.Kind, i))
+			items = append(items, item)
+		}
+		return items
+	}
+
+	t.Run("should list all resources", func(t *testing.T) {
+		// when
+		message := reconciler.blockingResourcesMessage(newItems(instanceGvk, 1), newItems(bindingGvk, 1))
+
+		// then
+		assert.Equal(t, "All service instances and bindings must be removed: 1 instance(s) and 1 binding(s): "+
+			"ServiceInstance test-namespace/ServiceInstance-0, ServiceBinding test-namespace/ServiceBinding-0", message)
+	})
+
+	t.Run("should bound the list", func(t *testing.T) {
+		// when
+		message := reconciler.blockingResourcesMessage(newItems(instanceGvk, maxReportedResources), newItems(bindingGvk, 2))
+
+		// then
+		assert.Contains(t, message, fmt.Sprintf("%d instance(s) and 2 binding(s)", maxReportedResources))
+		assert.NotContains(t, message, "ServiceBinding test-namespace/")
+		assert.Contains(t, message, " and 2 more")
+	})
+}
+
+func TestBtpOperatorReconciler_describeBlockingResource(t *testing.T) {
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+	now := time.Now()
+
+	t.Run("should describe a service instance", func(t *testing.T) {
+		// given
+		item := unstructured.Unstructured{}
+		item.SetGroupVersionKind(instanceGvk)
+		item.SetNamespace("test-namespace")
+		item.SetName(instanceName)
+		item.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Hour * 3)))
+		item.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "app"}})
+		_ = unstructured.SetNestedField(item.Object, "test-service", "spec", "serviceOfferingName")
+		_ = unstructured.SetNestedField(item.Object, "test-plan", "spec", "servicePlanName")
+
+		// when
+		description := reconciler.describeBlockingResource(reconciler.toBlockingResource(item), now)
+
+		// then
+		assert.Equal(t, "ServiceInstance test-namespace/my-service-instance (age: 3h; owners: Deployment/app; offering: test-service; plan: test-plan)", description)
+	})
+
+	t.Run("should describe a service binding", func(t *testing.T) {
+		// given
+		resource := v1alpha1.BlockingResource{
+			Kind:                btpOperatorServiceBinding,
+			Namespace:           "test-namespace",
+			Name:                bindingName,
+			CreationTimestamp:   metav1.NewTime(now.Add(-time.Minute * 5)),
+			ServiceInstanceName: instanceName,
+		}
+
+		// when
+		description := reconciler.describeBlockingResource(resource, now)
+
+		// then
+		assert.Equal(t, "ServiceBinding test-namespace/my-service-binding (age: 5m; instance: my-service-instance)", description)
+	})
+}
@@ -22,8 +22,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
+	"path"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -46,7 +47,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8sgenerictypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -56,6 +59,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // Configuration options that can be overwritten either by CLI parameter or ConfigMap
@@ -71,10 +75,22 @@ var (
 	HardDeleteTimeout              = time.Minute * 20
 	HardDeleteCheckInterval        = time.Second * 10
 	DeleteRequestTimeout           = time.Minute * 5
+	DeletionWorkers                = 5
+	DeletionRetryAttempts          = 3
+	DeletionRetryBackoff           = time.Second * 5
+	SoftDeleteStuckThreshold       = time.Minute * 10
+	SoftDeleteCheckInterval        = time.Minute
+	SoftDeleteKeepBindingSecrets   = false
 	StatusUpdateTimeout            = time.Second * 10
 	StatusUpdateCheckInterval      = time.Millisecond * 500
-	ChartPath                      = "./module-chart/chart"
-	ResourcesPath                  = "./module-resources"
+	ChartPath                      = ""
+	ResourcesPath                  = ""
+	RenderChart                    = false
+	ChartValuesConfigName          = "sap-btp-manager-chart-values"
+	ManifestsPublicKey             = ""
+	ImageRegistryMirrors           = ""
+	ImageDigests                   = ""
+	ImagePullSecrets               = ""
 )
 
 const (
@@ -82,16 +98,18 @@ const (
 	secretKind                         = "Secret"
 	configMapKind                      = "ConfigMap"
 	deploymentKind                     = "Deployment"
+	customResourceDefinitionKind       = "CustomResourceDefinition"
 	deploymentAvailableConditionType   = "Available"
 	deploymentProgressingConditionType = "Progressing"
 	operatorName                       = "btp-manager"
-	deletionFinalizer                  = "operator.kyma-project.io/btp-manager"
+	deletionFinalizer                  = v1alpha1.Finalizer
 	managedByLabelKey                  = "app.kubernetes.io/managed-by"
 	btpServiceOperatorConfigMap        = "sap-btp-operator-config"
 	btpServiceOperatorSecret           = "sap-btp-service-operator"
 	mutatingWebhookName                = "sap-btp-operator-mutating-webhook-configuration"
 	validatingWebhookName              = "sap-btp-operator-validating-webhook-configuration"
-	forceDeleteLabelKey                = "force-delete"
+	forceDeleteLabelKey                = v1alpha1.ForceDeleteLabelKey
+	orphaningMessage                   = "BtpOperator is to be deleted, Service Instances and Service Bindings are left in the cluster"
 )
 
 const (
@@ -132,6 +150,7 @@ var (
 type InstanceBindingSerivce interface {
 	DisableSISBController()
 	EnableSISBController()
+	SetServiceCRDs(available bool, instanceGvk, bindingGvk schema.GroupVersionKind)
 }
 
 // BtpOperatorReconciler reconciles a BtpOperator object
@@ -143,6 +162,10 @@ type BtpOperatorReconciler struct {
 	workqueueSize          int
 	metrics                *metrics.Metrics
 	instanceBindingService InstanceBindingSerivce
+	recorder               record.EventRecorder
+	serviceCRDs            *serviceCRDsWatcher
+	serviceVersions        *serviceVersions
+	discovery              discovery.DiscoveryInterface
 }
 
 type ResourceReadiness struct {
@@ -167,6 +190,8 @@ func NewBtpOperatorReconciler(client client.Client, scheme *runtime.Scheme, inst
 //+kubebuilder:rbac:groups="operator.kyma-project.io",resources="btpoperators/status",verbs="*"
 //+kubebuilder:rbac:groups="",resources="namespaces",verbs=get;list;watch
 //+kubebuilder:rbac:groups="services.cloud.sap.com",resources=serviceinstances;servicebindings,verbs="*"
+//+kubebuilder:rbac:groups="services.cloud.sap.com",resources=serviceinstances/status;servicebindings/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources="events",verbs=create;patch
 
 // Autogenerated RBAC from the btp-operator chart
 //+kubebuilder:rbac:groups="",resources="configmaps",verbs="*"
@@ -226,7 +251,19 @@ func (r *BtpOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, r.Update(ctx, reconcileCr)
 	}
 
-	if !reconcileCr.ObjectMeta.DeletionTimestamp.IsZero() && reconcileCr.Status.State != v1alpha1.StateDeleting && !reconcileCr.IsReasonStringEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned)) {
+	r.updateServiceCRDsCondition(ctx, reconcileCr)
+
+	if r.isDeprovisioningDryRun(reconcileCr) {
+		if err := r.handleDeprovisioningDryRun(ctx, reconcileCr); err != nil {
+			logger.Error(err, "deprovisioning dry run failed")
+		}
+	}
+
+	if !reconcileCr.ObjectMeta.DeletionTimestamp.IsZero() && reconcileCr.Status.State != v1alpha1.StateDeleting &&
+		!reconcileCr.IsReasonStringEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned)) && !reconcileCr.IsReasonStringEqual(string(conditions.ServiceResourcesKept)) {
+		if r.deletionPolicy(reconcileCr) == v1alpha1.DeletionPolicyOrphan {
+			return ctrl.Result{}, r.UpdateBtpOperatorStatus(ctx, reconcileCr, v1alpha1.StateDeleting, conditions.Orphaning, orphaningMessage)
+		}
 		return ctrl.Result{}, r.UpdateBtpOperatorStatus(ctx, reconcileCr, v1alpha1.StateDeleting, conditions.HardDeleting, "BtpOperator is to be deleted")
 	}
 
@@ -241,6 +278,10 @@ func (r *BtpOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, r.HandleErrorState(ctx, reconcileCr)
 	case v1alpha1.StateDeleting:
 		err := r.HandleDeletingState(ctx, reconcileCr)
+		var pendingErr *softDeletePendingError
+		if errors.As(err, &pendingErr) {
+			return ctrl.Result{RequeueAfter: SoftDeleteCheckInterval}, nil
+		}
 		if reconcileCr.IsReasonStringEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned)) {
 			return ctrl.Result{RequeueAfter: ReadyStateRequeueInterval}, err
 		}
@@ -344,12 +385,14 @@ func (r *BtpOperatorReconciler) HandleProcessingState(ctx context.Context, cr *v
 	}
 
 	if err := r.deleteOutdatedResources(ctx); err != nil {
-		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ProvisioningFailed, err.Error())
+		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, reasonForError(err, conditions.ProvisioningFailed), err.Error())
 	}
 
-	if err := r.reconcileResources(ctx, secret); err != nil {
-		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ProvisioningFailed, err.Error())
+	resources, err := r.reconcileResources(ctx, cr, secret)
+	if err != nil {
+		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, reasonForError(err, conditions.ProvisioningFailed), err.Error())
 	}
+	r.storeModuleResourcesStatus(ctx, cr, resources)
 
 	r.instanceBindingService.EnableSISBController()
 
@@ -444,7 +487,14 @@ func (r *BtpOperatorReconciler) deleteOutdatedResources(ctx context.Context) err
 }
 
 func (r *BtpOperatorReconciler) createUnstructuredObjectsFromManifestsDir(manifestsDir string) ([]*unstructured.Unstructured, error) {
-	objs, err := r.manifestHandler.CollectObjectsFromDir(manifestsDir)
+	publicKey, err := manifest.ParsePublicKey(ManifestsPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("while parsing manifests public key: %w", err)
+	}
+	fsys, _ := moduleResourcesFS()
+	handler := r.manifestHandler.WithFS(fsys)
+	handler.PublicKey = publicKey
+	objs, err := handler.CollectObjectsFromDir(manifestsDir)
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +507,8 @@ func (r *BtpOperatorReconciler) createUnstructuredObjectsFromManifestsDir(manife
 }
 
 func (r *BtpOperatorReconciler) getResourcesToDeletePath() string {
-	return fmt.Sprintf("%s%cdelete", ResourcesPath, os.PathSeparator)
+	_, resourcesPath := moduleResourcesFS()
+	return path.Join(resourcesPath, "delete")
 }
 
 func (r *BtpOperatorReconciler) deleteResources(ctx context.Context, us []*unstructured.Unstructured) error {
@@ -482,46 +533,93 @@ func (r *BtpOperatorReconciler) deleteResources(ctx context.Context, us []*unstr
 	return nil
 }
 
-func (r *BtpOperatorReconciler) reconcileResources(ctx context.Context, s *corev1.Secret) error {
+// reconcileResources applies module resources and returns them with the hash of the effective chart values and the result of patches
+func (r *BtpOperatorReconciler) reconcileResources(ctx context.Context, cr *v1alpha1.BtpOperator, s *corev1.Secret) (*moduleResources, error) {
 	logger := log.FromContext(ctx)
 
 	logger.Info("getting module resources to apply")
-	resourcesToApply, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToApplyPath())
+	resources, err := r.getResourcesToApply(ctx, cr, s)
 	if err != nil {
 		logger.Error(err, "while creating applicable objects from manifests")
-		return fmt.Errorf("failed to create applicable objects from manifests: %w", err)
+		return nil, fmt.Errorf("failed to create applicable objects from manifests: %w", err)
 	}
-	logger.Info(fmt.Sprintf("got %d module resources to apply based on %s directory", len(resourcesToApply), r.getResourcesToApplyPath()))
+	resourcesToApply := resources.objects
+	logger.Info(fmt.Sprintf("got %d module resources to apply based on %s", len(resourcesToApply), resources.source))
 
 	logger.Info("preparing module resources to apply")
 	if err = r.prepareModuleResourcesFromManifests(ctx, resourcesToApply, s); err != nil {
 		logger.Error(err, "while preparing objects to apply")
-		return fmt.Errorf("failed to prepare objects to apply: %w", err)
+		return nil, fmt.Errorf("failed to prepare objects to apply: %w", err)
+	}
+
+	if err = r.rewriteImages(resourcesToApply); err != nil {
+		logger.Error(err, "while rewriting images of module resources")
+		return nil, fmt.Errorf("failed to rewrite images of module resources: %w", err)
 	}
 
 	if err := r.prepareCertificatesReconciliationData(ctx, &resourcesToApply); err != nil {
-		return fmt.Errorf("failed to reconcile webhook certs: %w", err)
+		return nil, fmt.Errorf("failed to reconcile webhook certs: %w", err)
+	}
+
+	resources.patches = r.applyResourcePatches(ctx, resourcesToApply)
+	if condition := r.patchesCondition(resources.patches); condition != nil {
+		logger.Info("patched module resources", "status", condition.Status, "message", condition.Message)
 	}
 
 	r.deleteCreationTimestamp(resourcesToApply...)
 
+	logger.Info("validating module resources")
+	if err = r.validateResources(ctx, resourcesToApply); err != nil {
+		logger.Error(err, "while validating module resources")
+		return nil, fmt.Errorf("failed to validate module resources: %w", err)
+	}
+
+	previousSnapshot, err := r.getResourcesSnapshot(ctx)
+	if err != nil {
+		logger.Error(err, "while getting applied module resources snapshot, a failed upgrade won't be rolled back")
+	}
+	snapshot, err := r.newResourcesSnapshot(resourcesToApply)
+	if err != nil {
+		logger.Error(err, "while creating applied module resources snapshot")
+		return nil, fmt.Errorf("failed to create applied module resources snapshot: %w", err)
+	}
+
+	if previousSnapshot.isRolledBackUpgrade(snapshot) {
+		logger.Info(fmt.Sprintf("skipping upgrade from chart version %s to %s rolled back before", previousSnapshot.chartVersion, snapshot.chartVersion))
+		return nil, &upgradeRolledBackError{fromVersion: previousSnapshot.chartVersion, toVersion: snapshot.chartVersion, err: errUpgradeSkipped}
+	}
+
+	// the readiness waits while applying and after applying share the deadline, so they take at most ReadyTimeout together
+	readyCtx, cancel := context.WithTimeout(ctx, ReadyTimeout)
+	defer cancel()
+
 	logger.Info(fmt.Sprintf("applying module resources for %d resources", len(resourcesToApply)))
-	if err = r.applyOrUpdateResources(ctx, resourcesToApply); err != nil {
+	if err = r.applyOrUpdateResources(readyCtx, resourcesToApply); err != nil {
 		logger.Error(err, "while applying module resources")
-		return fmt.Errorf("failed to apply module resources: %w", err)
+		return nil, r.rollBackFailedUpgrade(ctx, previousSnapshot, snapshot, fmt.Errorf("failed to apply module resources: %w", err))
 	}
 
 	logger.Info("waiting for module resources readiness")
-	if err = r.waitForResourcesReadiness(ctx, resourcesToApply); err != nil {
+	if err = r.waitForResourcesReadiness(readyCtx, resourcesToApply); err != nil {
 		logger.Error(err, "while waiting for module resources readiness")
-		return fmt.Errorf("timed out while waiting for resources readiness: %w", err)
+		return nil, r.rollBackFailedUpgrade(ctx, previousSnapshot, snapshot, fmt.Errorf("timed out while waiting for resources readiness: %w", err))
 	}
 
-	return nil
+	if err = r.deleteResourcesRemovedSince(ctx, previousSnapshot, resourcesToApply); err != nil {
+		logger.Error(err, "while deleting module resources removed since the last reconciliation")
+		return nil, fmt.Errorf("failed to delete outdated module resources: %w", err)
+	}
+
+	if err = r.storeResourcesSnapshot(ctx, snapshot); err != nil {
+		logger.Error(err, "while storing applied module resources snapshot")
+	}
+
+	return resources, nil
 }
 
 func (r *BtpOperatorReconciler) getResourcesToApplyPath() string {
-	return fmt.Sprintf("%s%capply", ResourcesPath, os.PathSeparator)
+	_, resourcesPath := moduleResourcesFS()
+	return path.Join(resourcesPath, "apply")
 }
 
 func (r *BtpOperatorReconciler) prepareModuleResourcesFromManifests(ctx context.Context, resourcesToApply []*unstructured.Unstructured, s *corev1.Secret) error {
@@ -537,7 +635,8 @@ func (r *BtpOperatorReconciler) prepareModuleResourcesFromManifests(ctx context.
 		}
 	}
 
-	chartVer, err := ymlutils.ExtractStringValueFromYamlForGivenKey(fmt.Sprintf("%s/Chart.yaml", ChartPath), "version")
+	chartFS, chartPath := moduleChartFS()
+	chartVer, err := ymlutils.ExtractStringValueFromFSYamlForGivenKey(chartFS, path.Join(chartPath, "Chart.yaml"), "version")
 	if err != nil {
 		logger.Error(err, "while getting module chart version")
 		return fmt.Errorf("failed to get module chart version: %w", err)
@@ -596,30 +695,38 @@ func (r *BtpOperatorReconciler) setSecretValues(secret *corev1.Secret, u *unstru
 	return nil
 }
 
-func (r *BtpOperatorReconciler) applyOrUpdateResources(ctx context.Context, us []*unstructured.Unstructured) error {
+func (r *BtpOperatorReconciler) applyOrUpdateResource(ctx context.Context, u *unstructured.Unstructured) error {
 	logger := log.FromContext(ctx)
-	for _, u := range us {
-		preExistingResource := &unstructured.Unstructured{}
-		preExistingResource.SetGroupVersionKind(u.GroupVersionKind())
-		if err := r.Get(ctx, client.ObjectKey{Name: u.GetName(), Namespace: u.GetNamespace()}, preExistingResource); err != nil {
-			if !k8serrors.IsNotFound(err) {
-				return fmt.Errorf("while trying to get %s %s: %w", u.GetName(), u.GetKind(), err)
-			}
-			logger.Info(fmt.Sprintf("applying %s - %s", u.GetKind(), u.GetName()))
-			if err := r.Patch(ctx, u, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName)); err != nil {
-				return fmt.Errorf("while applying %s %s: %w", u.GetName(), u.GetKind(), err)
-			}
-		} else {
-			logger.Info(fmt.Sprintf("updating %s - %s", u.GetKind(), u.GetName()))
-			u.SetResourceVersion(preExistingResource.GetResourceVersion())
-			if err := r.Update(ctx, u, client.FieldOwner(operatorName)); err != nil {
-				return fmt.Errorf("while updating %s %s: %w", u.GetName(), u.GetKind(), err)
-			}
+	preExistingResource := &unstructured.Unstructured{}
+	preExistingResource.SetGroupVersionKind(u.GroupVersionKind())
+	if err := r.Get(ctx, client.ObjectKey{Name: u.GetName(), Namespace: u.GetNamespace()}, preExistingResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("while trying to get %s %s: %w", u.GetName(), u.GetKind(), err)
+		}
+		logger.Info(fmt.Sprintf("applying %s - %s", u.GetKind(), u.GetName()))
+		if err := r.Patch(ctx, u, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName)); err != nil {
+			return fmt.Errorf("while applying %s %s: %w", u.GetName(), u.GetKind(), err)
+		}
+	} else {
+		logger.Info(fmt.Sprintf("updating %s - %s", u.GetKind(), u.GetName()))
+		u.SetResourceVersion(preExistingResource.GetResourceVersion())
+		if err := r.Update(ctx, u, client.FieldOwner(operatorName)); err != nil {
+			return fmt.Errorf("while updating %s %s: %w", u.GetName(), u.GetKind(), err)
 		}
 	}
 	return nil
 }
 
+// readinessDeadline returns the deadline of the readiness checks, which is the deadline of the context if it's earlier
+// than ReadyTimeout from now, so that the checks can share the deadline with other readiness checks
+func readinessDeadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(ReadyTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
 func (r *BtpOperatorReconciler) waitForResourcesReadiness(ctx context.Context, us []*unstructured.Unstructured) error {
 	numOfResources := len(us)
 	resourcesReadinessInformer := make(chan ResourceReadiness, numOfResources)
@@ -647,9 +754,9 @@ func (r *BtpOperatorReconciler) checkDeploymentReadiness(ctx context.Context, u
 	var err error
 	var availableConditionStatus, progressingConditionStatus string
 	got := &appsv1.Deployment{}
-	now := time.Now()
+	deadline := readinessDeadline(ctx)
 	for {
-		if time.Since(now) >= ReadyTimeout {
+		if !time.Now().Before(deadline) {
 			logger.Error(err, fmt.Sprintf("timed out while checking %s %s readiness", u.GetName(), u.GetKind()))
 			c <- ResourceReadiness{
 				Name:      u.GetName(),
@@ -681,11 +788,11 @@ func (r *BtpOperatorReconciler) checkResourceExistence(ctx context.Context, u *u
 	defer cancel()
 
 	var err error
-	now := time.Now()
+	deadline := readinessDeadline(ctx)
 	got := &unstructured.Unstructured{}
 	got.SetGroupVersionKind(u.GroupVersionKind())
 	for {
-		if time.Since(now) >= ReadyTimeout {
+		if !time.Now().Before(deadline) {
 			logger.Error(err, fmt.Sprintf("timed out while checking %s %s existence", u.GetName(), u.GetKind()))
 			c <- ResourceReadiness{
 				Name:      u.GetName(),
@@ -713,6 +820,9 @@ func (r *BtpOperatorReconciler) HandleWarningState(ctx context.Context, cr *v1al
 		}
 		return ctrl.Result{}, err
 	}
+	if cr.IsReasonStringEqual(string(conditions.ServiceResourcesKept)) {
+		return ctrl.Result{}, r.handleDeleting(ctx, cr)
+	}
 
 	return ctrl.Result{}, r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateProcessing, conditions.Updated, "CR has been updated")
 }
@@ -739,19 +849,28 @@ func (r *BtpOperatorReconciler) handleDeleting(ctx context.Context, cr *v1alpha1
 		return nil
 	}
 
+	if cr.IsReasonStringEqual(string(conditions.ServiceResourcesKept)) {
+		return r.removeFinalizers(ctx, cr)
+	}
+
 	if err := r.handleDeprovisioning(ctx, cr); err != nil {
+		var pendingErr *softDeletePendingError
+		if errors.As(err, &pendingErr) {
+			logger.Info("Soft delete is waiting for Service Instances and Service Bindings in deletion", "pending", pendingErr.pending)
+			return err
+		}
 		logger.Error(err, "deprovisioning failed. Restoring resources")
-		r.reconcileResourcesWithoutChangingCrState(ctx, &logger)
+		r.reconcileResourcesWithoutChangingCrState(ctx, cr, &logger)
 		return err
 	}
 	if cr.IsReasonStringEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned)) {
-		r.reconcileResourcesWithoutChangingCrState(ctx, &logger)
+		r.reconcileResourcesWithoutChangingCrState(ctx, cr, &logger)
 
-		numberOfBindings, err := r.numberOfResources(ctx, bindingGvk)
+		numberOfBindings, err := r.numberOfResources(ctx, r.serviceBindingGvk())
 		if err != nil {
 			return err
 		}
-		numberOfInstances, err := r.numberOfResources(ctx, instanceGvk)
+		numberOfInstances, err := r.numberOfResources(ctx, r.serviceInstanceGvk())
 		if err != nil {
 			return err
 		}
@@ -761,11 +880,29 @@ func (r *BtpOperatorReconciler) handleDeleting(ctx context.Context, cr *v1alpha1
 		}
 	}
 
+	reported, err := r.reportKeptServiceResources(ctx, cr)
+	if err != nil {
+		return err
+	}
+	if reported {
+		logger.Info("Kept service resources reported. Finalizers will be removed in the next reconciliation")
+		return nil
+	}
+
+	return r.removeFinalizers(ctx, cr)
+}
+
+// removeFinalizers finishes the deprovisioning and lets the other BtpOperator CRs take over the module
+func (r *BtpOperatorReconciler) removeFinalizers(ctx context.Context, cr *v1alpha1.BtpOperator) error {
+	logger := log.FromContext(ctx)
+
 	r.instanceBindingService.DisableSISBController()
 
 	logger.Info("Deprovisioning success. Removing finalizers in CR")
+	// patch instead of update, because the deprovisioning progress in the status could have been updated in the meantime
+	patch := client.MergeFrom(cr.DeepCopy())
 	cr.SetFinalizers([]string{})
-	if err := r.Update(ctx, cr); err != nil {
+	if err := r.Patch(ctx, cr, patch); err != nil {
 		return err
 	}
 	existingBtpOperators := &v1alpha1.BtpOperatorList{}
@@ -789,31 +926,33 @@ func (r *BtpOperatorReconciler) handleDeleting(ctx context.Context, cr *v1alpha1
 func (r *BtpOperatorReconciler) handleDeprovisioning(ctx context.Context, cr *v1alpha1.BtpOperator) error {
 	logger := log.FromContext(ctx)
 
+	policy := r.deletionPolicy(cr)
+	if policy == v1alpha1.DeletionPolicyOrphan {
+		return r.handleOrphanDeprovisioning(ctx, cr)
+	}
+
 	namespaces := &corev1.NamespaceList{}
 	if err := r.List(ctx, namespaces); err != nil {
 		return err
 	}
 
-	if !r.IsForceDelete(cr) {
-		numberOfBindings, err := r.numberOfResources(ctx, bindingGvk)
-		if err != nil {
-			return err
-		}
-		numberOfInstances, err := r.numberOfResources(ctx, instanceGvk)
+	if policy == v1alpha1.DeletionPolicyBlock {
+		instances, bindings, err := r.listBlockingResources(ctx)
 		if err != nil {
 			return err
 		}
 
-		if numberOfBindings > 0 || numberOfInstances > 0 {
-			logger.Info(fmt.Sprintf("Existing resources (%d instances and %d bindings) block BTP Operator deletion.", numberOfInstances, numberOfBindings))
-			msg := fmt.Sprintf("All service instances and bindings must be removed: %d instance(s) and %d binding(s)", numberOfInstances, numberOfBindings)
+		if len(bindings) > 0 || len(instances) > 0 {
+			logger.Info(fmt.Sprintf("Existing resources (%d instances and %d bindings) block BTP Operator deletion.", len(instances), len(bindings)))
+			msg := r.blockingResourcesMessage(instances, bindings)
 			logger.Info(msg)
 
-			// if the reason is already set, do nothing
-			if cr.IsReasonStringEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned)) && cr.Status.State == v1alpha1.StateWarning {
+			// if the blocking resources are already reported, do nothing
+			if cr.IsMsgForGivenReasonEqual(string(conditions.ServiceInstancesAndBindingsNotCleaned), msg) && cr.Status.State == v1alpha1.StateWarning {
 				return nil
 			}
 
+			r.reportBlockingResources(ctx, cr, instances, bindings)
 			if updateStatusErr := r.UpdateBtpOperatorStatus(ctx, cr,
 				v1alpha1.StateWarning, conditions.ServiceInstancesAndBindingsNotCleaned, msg); updateStatusErr != nil {
 				return updateStatusErr
@@ -830,17 +969,26 @@ func (r *BtpOperatorReconciler) handleDeprovisioning(ctx context.Context, cr *v1
 		}
 	}
 
-	hardDeleteSucceededCh := make(chan bool, 1)
-	hardDeleteTimeoutReachedCh := make(chan bool, 1)
-	defer close(hardDeleteTimeoutReachedCh)
+	if cr.Status.State == v1alpha1.StateDeleting && cr.IsReasonStringEqual(string(conditions.SoftDeleting)) {
+		logger.Info("Hard delete already failed. Resuming soft delete")
+		if err := r.handleSoftDelete(ctx, cr, nil); err != nil {
+			logger.Error(err, "failed to soft delete")
+			return err
+		}
+		return nil
+	}
+
+	hardDeleteResultCh := make(chan hardDeleteResult, 1)
+	hardDeleteCtx, cancel := context.WithTimeout(ctx, HardDeleteTimeout)
+	defer cancel()
 
-	go r.handleHardDelete(ctx, namespaces, hardDeleteSucceededCh, hardDeleteTimeoutReachedCh)
+	go r.handleHardDelete(hardDeleteCtx, cr.DeepCopy(), namespaces, hardDeleteResultCh)
 
 	select {
-	case hardDeleteSucceeded := <-hardDeleteSucceededCh:
-		if hardDeleteSucceeded {
+	case result := <-hardDeleteResultCh:
+		if result.succeeded {
 			logger.Info("Service Instances and Service Bindings hard delete succeeded. Removing module resources")
-			if err := r.deleteBtpOperatorResources(ctx); err != nil {
+			if err := r.deleteBtpOperatorResources(ctx, r.keptKinds(cr)...); err != nil {
 				logger.Error(err, "failed to remove module resources")
 				if updateStatusErr := r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ResourceRemovalFailed, "Unable to remove installed resources"); updateStatusErr != nil {
 					logger.Error(updateStatusErr, "failed to update status")
@@ -854,19 +1002,21 @@ func (r *BtpOperatorReconciler) handleDeprovisioning(ctx context.Context, cr *v1
 				logger.Error(err, "failed to update status")
 				return err
 			}
-			if err := r.handleSoftDelete(ctx, namespaces); err != nil {
+			if err := r.handleSoftDelete(ctx, cr, result.failedNamespaces); err != nil {
 				logger.Error(err, "failed to soft delete")
 				return err
 			}
 		}
-	case <-time.After(HardDeleteTimeout):
+	case <-hardDeleteCtx.Done():
 		logger.Info("hard delete timeout reached", "duration", HardDeleteTimeout)
-		hardDeleteTimeoutReachedCh <- true
+		// wait until the hard delete stops, so that it doesn't update the status during the soft delete
+		for range hardDeleteResultCh {
+		}
 		if err := r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateDeleting, conditions.SoftDeleting, "Being soft deleted"); err != nil {
 			logger.Error(err, "failed to update status")
 			return err
 		}
-		if err := r.handleSoftDelete(ctx, namespaces); err != nil {
+		if err := r.handleSoftDelete(ctx, cr, nil); err != nil {
 			logger.Error(err, "failed to soft delete")
 			return err
 		}
@@ -875,12 +1025,47 @@ func (r *BtpOperatorReconciler) handleDeprovisioning(ctx context.Context, cr *v1
 	return nil
 }
 
-func (r *BtpOperatorReconciler) handleHardDelete(ctx context.Context, namespaces *corev1.NamespaceList, hardDeleteSucceededCh, hardDeleteTimeoutReachedCh chan bool) {
+// handleOrphanDeprovisioning removes module components, but keeps ServiceInstances, ServiceBindings and their CRDs,
+// so that SAP BTP service operator installed again takes them over
+func (r *BtpOperatorReconciler) handleOrphanDeprovisioning(ctx context.Context, cr *v1alpha1.BtpOperator) error {
+	logger := log.FromContext(ctx)
+	logger.Info("Deprovisioning BTP Operator - orphaning Service Instances and Service Bindings")
+
+	if !cr.IsReasonStringEqual(string(conditions.Orphaning)) {
+		if err := r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateDeleting, conditions.Orphaning, orphaningMessage); err != nil {
+			logger.Error(err, "failed to update status")
+			return err
+		}
+	}
+
+	if err := r.deleteBtpOperatorResources(ctx, customResourceDefinitionKind); err != nil {
+		logger.Error(err, "failed to remove module resources")
+		if updateStatusErr := r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ResourceRemovalFailed, "Unable to remove installed resources"); updateStatusErr != nil {
+			logger.Error(updateStatusErr, "failed to update status")
+			return updateStatusErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// hardDeleteResult carries namespaces with failed delete requests, so that the soft delete is applied only where it is needed
+type hardDeleteResult struct {
+	succeeded        bool
+	failedNamespaces []string
+}
+
+// handleHardDelete deletes ServiceBindings and ServiceInstances and waits until they are gone. The context has the HardDeleteTimeout
+// deadline, after which the hard delete stops without sending the result.
+func (r *BtpOperatorReconciler) handleHardDelete(ctx context.Context, cr *v1alpha1.BtpOperator, namespaces *corev1.NamespaceList, hardDeleteResultCh chan hardDeleteResult) {
 	logger := log.FromContext(ctx)
 	logger.Info("Deprovisioning BTP Operator - hard delete")
-	defer close(hardDeleteSucceededCh)
+	defer close(hardDeleteResultCh)
+	instanceGvk, bindingGvk := r.serviceInstanceGvk(), r.serviceBindingGvk()
 
 	errs := make([]error, 0)
+	gvks := make([]schema.GroupVersionKind, 0)
 
 	sbCrdExists, err := r.crdExists(ctx, bindingGvk)
 	if err != nil {
@@ -888,12 +1073,7 @@ func (r *BtpOperatorReconciler) handleHardDelete(ctx context.Context, namespaces
 		errs = append(errs, err)
 	}
 	if sbCrdExists {
-		if err := r.hardDelete(ctx, bindingGvk, namespaces); err != nil {
-			logger.Error(err, "while deleting Service Bindings")
-			if !errors.Is(err, context.DeadlineExceeded) {
-				errs = append(errs, err)
-			}
-		}
+		gvks = append(gvks, bindingGvk)
 	}
 
 	siCrdExists, err := r.crdExists(ctx, instanceGvk)
@@ -902,32 +1082,41 @@ func (r *BtpOperatorReconciler) handleHardDelete(ctx context.Context, namespaces
 		errs = append(errs, err)
 	}
 	if siCrdExists {
-		if err := r.hardDelete(ctx, instanceGvk, namespaces); err != nil {
-			logger.Error(err, "while deleting Service Instances")
-			if !errors.Is(err, context.DeadlineExceeded) {
-				errs = append(errs, err)
-			}
-		}
+		gvks = append(gvks, instanceGvk)
 	}
 
 	if len(errs) > 0 {
-		hardDeleteSucceededCh <- false
+		hardDeleteResultCh <- hardDeleteResult{succeeded: false}
+		return
+	}
+
+	namespaceNames := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		namespaceNames = append(namespaceNames, namespace.Name)
+	}
+	outcomes := r.deleteServiceResourcesInNamespaces(ctx, gvks, namespaceNames)
+	for namespace, err := range outcomes {
+		if err != nil {
+			logger.Error(err, "while deleting Service Bindings and Service Instances", "namespace", namespace)
+		}
+	}
+	if failedNamespaces := r.failedNamespaces(ctx, outcomes); len(failedNamespaces) > 0 {
+		r.updateDeprovisioningProgress(ctx, cr, outcomes)
+		hardDeleteResultCh <- hardDeleteResult{succeeded: false, failedNamespaces: failedNamespaces}
 		return
 	}
 
 	var sbResourcesLeft, siResourcesLeft bool
 	for {
-		select {
-		case <-hardDeleteTimeoutReachedCh:
+		if ctx.Err() != nil {
 			return
-		default:
 		}
 
 		if sbCrdExists {
 			sbResourcesLeft, err = r.resourcesExist(ctx, namespaces, bindingGvk)
 			if err != nil {
 				logger.Error(err, "ServiceBinding leftover resources check failed")
-				hardDeleteSucceededCh <- false
+				hardDeleteResultCh <- hardDeleteResult{succeeded: false}
 				return
 			}
 		}
@@ -936,25 +1125,36 @@ func (r *BtpOperatorReconciler) handleHardDelete(ctx context.Context, namespaces
 			siResourcesLeft, err = r.resourcesExist(ctx, namespaces, instanceGvk)
 			if err != nil {
 				logger.Error(err, "ServiceInstance leftover resources check failed")
-				hardDeleteSucceededCh <- false
+				hardDeleteResultCh <- hardDeleteResult{succeeded: false}
 				return
 			}
 		}
 
 		if !sbResourcesLeft && !siResourcesLeft {
-			hardDeleteSucceededCh <- true
+			hardDeleteResultCh <- hardDeleteResult{succeeded: true}
 			return
 		}
 
-		time.Sleep(HardDeleteCheckInterval)
+		r.updateDeprovisioningProgress(ctx, cr, outcomes)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(HardDeleteCheckInterval):
+		}
 	}
 }
 
+// crdExists answers from the serviceCRDsWatcher for ServiceInstances and ServiceBindings once its informer has synced
 func (r *BtpOperatorReconciler) crdExists(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
-	crdName := fmt.Sprintf("%ss.%s", strings.ToLower(gvk.Kind), gvk.Group)
-	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if r.serviceCRDs != nil && gvk.Group == serviceCRDsGroup {
+		if exists, synced := r.serviceCRDs.exists(gvk); synced {
+			return exists, nil
+		}
+	}
 
-	if err := r.Get(ctx, client.ObjectKey{Name: crdName}, crd); err != nil {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := r.Get(ctx, client.ObjectKey{Name: crdName(gvk)}, crd); err != nil {
 		if k8serrors.IsNotFound(err) {
 			return false, nil
 		} else {
@@ -964,21 +1164,6 @@ func (r *BtpOperatorReconciler) crdExists(ctx context.Context, gvk schema.GroupV
 	return true, nil
 }
 
-func (r *BtpOperatorReconciler) hardDelete(ctx context.Context, gvk schema.GroupVersionKind, namespaces *corev1.NamespaceList) error {
-	object := &unstructured.Unstructured{}
-	object.SetGroupVersionKind(gvk)
-	deleteCtx, cancel := context.WithTimeout(ctx, DeleteRequestTimeout)
-	defer cancel()
-
-	for _, namespace := range namespaces.Items {
-		if err := r.DeleteAllOf(deleteCtx, object, client.InNamespace(namespace.Name)); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (r *BtpOperatorReconciler) resourcesExist(ctx context.Context, namespaces *corev1.NamespaceList, gvk schema.GroupVersionKind) (bool, error) {
 	anyLeft := func(namespace string, gvk schema.GroupVersionKind) (bool, error) {
 		list := &unstructured.UnstructuredList{}
@@ -1023,27 +1208,25 @@ func (r *BtpOperatorReconciler) numberOfResources(ctx context.Context, gvk schem
 	return len(list.Items), nil
 }
 
-func (r *BtpOperatorReconciler) deleteBtpOperatorResources(ctx context.Context) error {
+// deleteBtpOperatorResources deletes all module resources except the ones of the given kinds
+func (r *BtpOperatorReconciler) deleteBtpOperatorResources(ctx context.Context, skipKinds ...string) error {
 	logger := log.FromContext(ctx)
 
 	logger.Info("getting module resources to delete")
-	resourcesToDeleteFromApply, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToApplyPath())
+	moduleResources, err := r.getModuleResourcesToDelete(ctx)
 	if err != nil {
 		logger.Error(err, "while getting objects to delete from manifests")
 		return fmt.Errorf("Failed to create deletable objects from manifests: %w", err)
 	}
-	logger.Info(fmt.Sprintf("got %d module resources to delete from \"apply\" dir", len(resourcesToDeleteFromApply)))
-
-	resourcesToDeleteFromDelete, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToDeletePath())
-	if err != nil {
-		logger.Error(err, "while getting objects to delete from manifests")
-		return fmt.Errorf("Failed to create deletable objects from manifests: %w", err)
-	}
-	logger.Info(fmt.Sprintf("got %d module resources to delete from \"delete\" dir", len(resourcesToDeleteFromDelete)))
+	logger.Info(fmt.Sprintf("got %d module resources to delete", len(moduleResources)))
 
 	resourcesToDelete := make([]*unstructured.Unstructured, 0)
-	resourcesToDelete = append(resourcesToDelete, resourcesToDeleteFromApply...)
-	resourcesToDelete = append(resourcesToDelete, resourcesToDeleteFromDelete...)
+	for _, u := range moduleResources {
+		if slices.Contains(skipKinds, u.GetKind()) {
+			continue
+		}
+		resourcesToDelete = append(resourcesToDelete, u)
+	}
 
 	if err = r.deleteAllOfResourcesTypes(ctx, resourcesToDelete...); err != nil {
 		logger.Error(err, "while deleting module resources")
@@ -1073,15 +1256,20 @@ func (r *BtpOperatorReconciler) deleteAllOfResourcesTypes(ctx context.Context, r
 	return nil
 }
 
-func (r *BtpOperatorReconciler) handleSoftDelete(ctx context.Context, namespaces *corev1.NamespaceList) error {
+// handleSoftDelete removes finalizers only in the namespaces where the hard delete failed, or in all namespaces where ServiceInstances or
+// ServiceBindings are still present if the hard delete timed out.
+// Only resources stuck in deletion are orphaned, and sap-btp-service-operator is kept until the other resources are deleted.
+func (r *BtpOperatorReconciler) handleSoftDelete(ctx context.Context, cr *v1alpha1.BtpOperator, failedNamespaces []string) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Deprovisioning BTP Operator - soft delete")
+	instanceGvk, bindingGvk := r.serviceInstanceGvk(), r.serviceBindingGvk()
 
-	logger.Info("Deleting module deployment and webhooks")
-	if err := r.preSoftDeleteCleanup(ctx); err != nil {
-		logger.Error(err, "module deployment and webhooks deletion failed")
+	namespaces, err := r.namespacesToSoftDelete(ctx, failedNamespaces)
+	if err != nil {
+		logger.Error(err, "while getting namespaces to soft delete")
 		return err
 	}
+	logger.Info("Soft deleting Service Instances and Service Bindings", "namespaces", namespaces)
 
 	sbCrdExists, err := r.crdExists(ctx, bindingGvk)
 	if err != nil {
@@ -1095,12 +1283,46 @@ func (r *BtpOperatorReconciler) handleSoftDelete(ctx context.Context, namespaces
 		return err
 	}
 
+	gvks := make([]schema.GroupVersionKind, 0)
+	if sbCrdExists {
+		gvks = append(gvks, bindingGvk)
+	}
+	if siCrdExists {
+		gvks = append(gvks, instanceGvk)
+	}
+	if err := r.deleteResourcesNotInDeletion(ctx, gvks, namespaces); err != nil {
+		logger.Error(err, "while deleting Service Instances and Service Bindings which are not in deletion")
+		return err
+	}
+	toOrphan, pending, err := r.selectResourcesToOrphan(ctx, gvks, namespaces)
+	if err != nil {
+		logger.Error(err, "while selecting Service Instances and Service Bindings stuck in deletion")
+		return err
+	}
+
+	logger.Info("Backing up Service Instances and Service Bindings")
+	if err := r.backupServiceResources(ctx, cr, toOrphan); err != nil {
+		logger.Error(err, "backup of Service Instances and Service Bindings failed")
+		return err
+	}
+
+	if pending > 0 {
+		return &softDeletePendingError{pending: pending}
+	}
+
+	logger.Info("Deleting module deployment and webhooks")
+	if err := r.preSoftDeleteCleanup(ctx); err != nil {
+		logger.Error(err, "module deployment and webhooks deletion failed")
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Removing finalizers in %d Service Bindings and Service Instances stuck in deletion", len(toOrphan)))
+	if err := r.softDelete(ctx, cr, toOrphan); err != nil {
+		logger.Error(err, "while removing finalizers")
+		return err
+	}
+
 	if sbCrdExists {
-		logger.Info("Removing finalizers in Service Bindings and deleting connected Secrets")
-		if err := r.softDelete(ctx, bindingGvk); err != nil {
-			logger.Error(err, "while deleting Service Bindings")
-			return err
-		}
 		if err := r.ensureResourcesDontExist(ctx, bindingGvk); err != nil {
 			logger.Error(err, "Service Bindings still exist")
 			return err
@@ -1108,11 +1330,6 @@ func (r *BtpOperatorReconciler) handleSoftDelete(ctx context.Context, namespaces
 	}
 
 	if siCrdExists {
-		logger.Info("Removing finalizers in Service Instances")
-		if err := r.softDelete(ctx, instanceGvk); err != nil {
-			logger.Error(err, "while deleting Service Instances")
-			return err
-		}
 		if err := r.ensureResourcesDontExist(ctx, instanceGvk); err != nil {
 			logger.Error(err, "Service Instances still exist")
 			return err
@@ -1120,7 +1337,7 @@ func (r *BtpOperatorReconciler) handleSoftDelete(ctx context.Context, namespaces
 	}
 
 	logger.Info("Deleting module resources")
-	if err := r.deleteBtpOperatorResources(ctx); err != nil {
+	if err := r.deleteBtpOperatorResources(ctx, r.keptKinds(cr)...); err != nil {
 		logger.Error(err, "failed to delete module resources")
 		return err
 	}
@@ -1165,34 +1382,31 @@ func (r *BtpOperatorReconciler) preSoftDeleteCleanup(ctx context.Context) error
 	return nil
 }
 
-func (r *BtpOperatorReconciler) softDelete(ctx context.Context, gvk schema.GroupVersionKind) error {
-	list := r.GvkToList(gvk)
-
-	if err := r.List(ctx, list); err != nil {
-		return fmt.Errorf("%w; could not list in soft delete", err)
-	}
+// softDelete removes finalizers from the given resources and deletes or keeps Secrets of ServiceBindings
+func (r *BtpOperatorReconciler) softDelete(ctx context.Context, cr *v1alpha1.BtpOperator, items []unstructured.Unstructured) error {
+	logger := log.FromContext(ctx)
 
-	isBinding := gvk.Kind == btpOperatorServiceBinding
-	for _, item := range list.Items {
-		if item.GetDeletionTimestamp().IsZero() {
-			if err := r.Delete(ctx, &item); err != nil {
-				return err
-			}
-		}
-		item.SetFinalizers([]string{})
-		if err := r.Update(ctx, &item); err != nil {
+	unresolved := make([]v1alpha1.UnresolvedBindingSecret, 0)
+	for _, item := range items {
+		// patch instead of update, because the listed resource could have been updated by sap-btp-service-operator in the meantime
+		patch := client.MergeFrom(item.DeepCopy())
+		item.SetFinalizers(nil)
+		if err := r.Patch(ctx, &item, patch); client.IgnoreNotFound(err) != nil {
 			return err
 		}
+		logger.Info("orphaned resource stuck in deletion", "kind", item.GetKind(), "namespace", item.GetNamespace(), "name", item.GetName())
 
-		if isBinding {
-			secret := &corev1.Secret{}
-			secret.Name = item.GetName()
-			secret.Namespace = item.GetNamespace()
-			if err := r.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+		if item.GetKind() == btpOperatorServiceBinding {
+			message, err := r.handleBindingSecret(ctx, item)
+			if err != nil {
 				return err
 			}
+			if message != "" {
+				unresolved = append(unresolved, v1alpha1.UnresolvedBindingSecret{Namespace: item.GetNamespace(), Name: item.GetName(), Message: message})
+			}
 		}
 	}
+	r.reportUnresolvedBindingSecrets(ctx, cr, unresolved)
 
 	return nil
 }
@@ -1229,11 +1443,20 @@ func (r *BtpOperatorReconciler) HandleReadyState(ctx context.Context, cr *v1alph
 	}
 
 	if err := r.deleteOutdatedResources(ctx); err != nil {
-		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ReconcileFailed, err.Error())
+		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, reasonForError(err, conditions.ReconcileFailed), err.Error())
 	}
 
-	if err := r.reconcileResources(ctx, secret); err != nil {
-		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, conditions.ReconcileFailed, err.Error())
+	resources, err := r.reconcileResources(ctx, cr, secret)
+	if err != nil {
+		return r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateError, reasonForError(err, conditions.ReconcileFailed), err.Error())
+	}
+	r.storeModuleResourcesStatus(ctx, cr, resources)
+
+	if r.isRestoreRequested(cr) {
+		if err := r.restoreServiceResources(ctx, cr); err != nil {
+			logger.Error(err, "restore of Service Instances and Service Bindings failed")
+			return err
+		}
 	}
 
 	logger.Info("reconciliation succeeded")
@@ -1243,6 +1466,21 @@ func (r *BtpOperatorReconciler) HandleReadyState(ctx context.Context, cr *v1alph
 // SetupWithManager sets up the controller with the Manager.
 func (r *BtpOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Config = mgr.GetConfig()
+	r.recorder = mgr.GetEventRecorderFor(operatorName)
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("while creating discovery client: %w", err)
+	}
+	r.serviceVersions = newServiceVersions(mgr.GetClient())
+	r.discovery = discoveryClient
+	r.serviceCRDs = newServiceCRDsWatcher(r.onServiceCRDsChange)
+	if err := r.serviceCRDs.register(context.Background(), mgr.GetCache()); err != nil {
+		return err
+	}
+	configMapsCache, err := newConfigMapsMetadataCache(mgr)
+	if err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.BtpOperator{},
 			builder.WithPredicates(r.watchBtpOperatorUpdatePredicate())).
@@ -1271,6 +1509,9 @@ func (r *BtpOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(r.reconcileRequestForOldestBtpOperator),
 			builder.WithPredicates(r.watchDeploymentPredicates()),
 		).
+		WatchesRawSource(source.Channel(r.serviceCRDs.events, handler.EnqueueRequestsFromMapFunc(r.reconcileRequestForOldestBtpOperator))).
+		WatchesRawSource(source.Kind[client.Object](configMapsCache, configMapMetadata(),
+			handler.EnqueueRequestsFromMapFunc(r.reconcileRequestForOldestBtpOperator), r.watchChartValuesConfigPredicates())).
 		Complete(r)
 }
 
@@ -1493,10 +1734,40 @@ func (r *BtpOperatorReconciler) reconcileConfig(ctx context.Context, obj client.
 			HardDeleteTimeout, err = time.ParseDuration(v)
 		case "ResourcesPath":
 			ResourcesPath = v
+		case "RenderChart":
+			RenderChart, err = strconv.ParseBool(v)
+		case "ChartValuesConfigName":
+			ChartValuesConfigName = v
+		case "ManifestsPublicKey":
+			if _, err = manifest.ParsePublicKey(v); err == nil {
+				ManifestsPublicKey = v
+			}
+		case "ImageRegistryMirrors":
+			if _, err = parseRegistryMirrors(v); err == nil {
+				ImageRegistryMirrors = v
+			}
+		case "ImageDigests":
+			if _, err = parseImageDigests(v); err == nil {
+				ImageDigests = v
+			}
+		case "ImagePullSecrets":
+			ImagePullSecrets = v
 		case "ReadyCheckInterval":
 			ReadyCheckInterval, err = time.ParseDuration(v)
 		case "DeleteRequestTimeout":
 			DeleteRequestTimeout, err = time.ParseDuration(v)
+		case "DeletionWorkers":
+			DeletionWorkers, err = strconv.Atoi(v)
+		case "DeletionRetryAttempts":
+			DeletionRetryAttempts, err = strconv.Atoi(v)
+		case "DeletionRetryBackoff":
+			DeletionRetryBackoff, err = time.ParseDuration(v)
+		case "SoftDeleteStuckThreshold":
+			SoftDeleteStuckThreshold, err = time.ParseDuration(v)
+		case "SoftDeleteCheckInterval":
+			SoftDeleteCheckInterval, err = time.ParseDuration(v)
+		case "SoftDeleteKeepBindingSecrets":
+			SoftDeleteKeepBindingSecrets, err = strconv.ParseBool(v)
 		case "CaCertificateExpiration":
 			CaCertificateExpiration, err = time.ParseDuration(v)
 		case "WebhookCertificateExpiration":
@@ -1520,6 +1791,21 @@ func (r *BtpOperatorReconciler) reconcileConfig(ctx context.Context, obj client.
 	return r.enqueueOldestBtpOperator()
 }
 
+// watchChartValuesConfigPredicates matches the chart values ConfigMap and the patches ConfigMaps in the ConfigMaps metadata cache
+func (r *BtpOperatorReconciler) watchChartValuesConfigPredicates() predicate.Funcs {
+	matches := func(o client.Object) bool {
+		if o.GetNamespace() != ChartNamespace {
+			return false
+		}
+		return o.GetName() == ChartValuesConfigName || o.GetLabels()[v1alpha1.ModuleResourcesPatchLabelKey] == "true"
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return matches(e.Object) },
+		DeleteFunc: func(e event.DeleteEvent) bool { return matches(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool { return matches(e.ObjectOld) || matches(e.ObjectNew) },
+	}
+}
+
 func (r *BtpOperatorReconciler) watchConfigPredicates() predicate.Funcs {
 	nameMatches := func(o client.Object) bool { return o.GetName() == ConfigName && o.GetNamespace() == ChartNamespace }
 	return predicate.Funcs{
@@ -1529,6 +1815,18 @@ func (r *BtpOperatorReconciler) watchConfigPredicates() predicate.Funcs {
 	}
 }
 
+// deletionPolicy returns the policy from the spec, the force-delete label works as the Delete policy if the spec doesn't set Delete or Orphan
+func (r *BtpOperatorReconciler) deletionPolicy(cr *v1alpha1.BtpOperator) v1alpha1.DeletionPolicy {
+	switch cr.Spec.DeletionPolicy {
+	case v1alpha1.DeletionPolicyDelete, v1alpha1.DeletionPolicyOrphan:
+		return cr.Spec.DeletionPolicy
+	}
+	if r.IsForceDelete(cr) {
+		return v1alpha1.DeletionPolicyDelete
+	}
+	return v1alpha1.DeletionPolicyBlock
+}
+
 func (r *BtpOperatorReconciler) IsForceDelete(cr *v1alpha1.BtpOperator) bool {
 	if _, exists := cr.Labels[forceDeleteLabelKey]; !exists {
 		return false
@@ -2058,7 +2356,7 @@ func (r *BtpOperatorReconciler) buildSecretWithDataAndLabels(name string, data m
 	}
 }
 
-func (r *BtpOperatorReconciler) reconcileResourcesWithoutChangingCrState(ctx context.Context, logger *logr.Logger) {
+func (r *BtpOperatorReconciler) reconcileResourcesWithoutChangingCrState(ctx context.Context, cr *v1alpha1.BtpOperator, logger *logr.Logger) {
 	secret, errWithReason := r.getAndVerifyRequiredSecret(ctx)
 	if errWithReason != nil {
 		logger.Error(errWithReason, "secret verification failed")
@@ -2066,7 +2364,7 @@ func (r *BtpOperatorReconciler) reconcileResourcesWithoutChangingCrState(ctx con
 	if err := r.deleteOutdatedResources(ctx); err != nil {
 		logger.Error(err, "outdated resources deletion failed")
 	}
-	if err := r.reconcileResources(ctx, secret); err != nil {
+	if _, err := r.reconcileResources(ctx, cr, secret); err != nil {
 		logger.Error(err, "resources reconciliation failed")
 	}
 }
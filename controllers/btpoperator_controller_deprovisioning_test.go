@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/kyma-project/btp-manager/api/v1alpha1"
 	"github.com/kyma-project/btp-manager/internal/conditions"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -59,10 +65,57 @@ var _ = Describe("BTP Operator controller - deprovisioning", func() {
 			Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
 
 			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateWarning, metav1.ConditionFalse, conditions.ServiceInstancesAndBindingsNotCleaned)))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			Expect(cr.Status.Deprovisioning).NotTo(BeNil())
+			Expect(cr.Status.Deprovisioning.BlockingResources).To(ConsistOf(
+				SatisfyAll(HaveField("Kind", btpOperatorServiceInstance), HaveField("Name", instanceName), HaveField("ServiceOfferingName", "test-service")),
+				SatisfyAll(HaveField("Kind", btpOperatorServiceBinding), HaveField("Name", bindingName), HaveField("ServiceInstanceName", "test-service-instance")),
+			))
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(defaultNamespace))).To(Succeed())
+				g.Expect(events.Items).To(ContainElement(HaveField("Reason", deletionBlockedEventReason)))
+			}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+		})
+
+		It("Dry run should report instances and bindings without deleting them", func() {
+			_ = createResource(instanceGvk, kymaNamespace, instanceName)
+			ensureResourceExists(instanceGvk)
+
+			_ = createResource(bindingGvk, kymaNamespace, bindingName)
+			ensureResourceExists(bindingGvk)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			cr.SetAnnotations(map[string]string{v1alpha1.DeprovisioningDryRunAnnotation: "true"})
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+			report := &DeprovisioningReport{}
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: deprovisioningReportName}, cm)).To(Succeed())
+				g.Expect(yaml.Unmarshal([]byte(cm.Data[deprovisioningReportKey]), report)).To(Succeed())
+			}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+
+			Expect(report.Blocked).To(BeTrue())
+			Expect(report.ServiceInstances).To(HaveKeyWithValue(kymaNamespace, ConsistOf(instanceName)))
+			Expect(report.ServiceBindings).To(HaveKeyWithValue(kymaNamespace, ConsistOf(bindingName)))
+			Expect(report.CustomResourceDefinitions).NotTo(BeEmpty())
+			Expect(report.ModuleResources).To(ContainElement(HaveField("Name", DeploymentName)))
+
+			ensureResourceExists(instanceGvk)
+			ensureResourceExists(bindingGvk)
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: DeploymentName, Namespace: kymaNamespace}, &appsv1.Deployment{})).To(Succeed())
 		})
 
 	})
 
+	// setSoftDeleteStuckThreshold sets the threshold for the current spec only, so resources in deletion are orphaned without waiting
+	setSoftDeleteStuckThreshold := func(threshold time.Duration) {
+		DeferCleanup(func(defaultThreshold time.Duration) { SoftDeleteStuckThreshold = defaultThreshold }, SoftDeleteStuckThreshold)
+		SoftDeleteStuckThreshold = threshold
+	}
+
 	Describe("Deprovisioning with force-delete label", func() {
 		var siUnstructured, sbUnstructured *unstructured.Unstructured
 
@@ -89,22 +142,34 @@ var _ = Describe("BTP Operator controller - deprovisioning", func() {
 			deleteSecret := &corev1.Secret{}
 			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: SecretName}, deleteSecret)).To(Succeed())
 			Expect(k8sClient.Delete(ctx, deleteSecret)).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(kymaNamespace), backupLabelFilter)).To(Succeed())
 		})
 
 		It("soft delete (after timeout) should succeed", func() {
 			reconciler.Client = newTimeoutK8sClient(reconciler.Client)
+			setSoftDeleteStuckThreshold(0)
 			setFinalizers(siUnstructured)
 			setFinalizers(sbUnstructured)
 			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
 			Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
 			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.HardDeleting)))
+			Eventually(updateCh).Should(Receive(matchDeprovisioningProgress(kymaNamespace, 1, 1)))
 			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.SoftDeleting)))
 			Eventually(updateCh).Should(Receive(matchDeleted()))
 			doChecks()
+
+			backupEntries := getBackupEntries()
+			Expect(backupEntries).To(HaveKey(fmt.Sprintf("%s/%s/%s", btpOperatorServiceInstance, kymaNamespace, instanceName)))
+			Expect(backupEntries).To(HaveKey(fmt.Sprintf("%s/%s/%s", btpOperatorServiceBinding, kymaNamespace, bindingName)))
+			for _, entry := range backupEntries {
+				orphaned := unstructured.Unstructured{Object: entry}
+				Expect(orphaned.GetAnnotations()).To(HaveKey(forceOrphanedAnnotation))
+			}
 		})
 
 		It("soft delete (after hard deletion fail) should succeed", func() {
 			reconciler.Client = newErrorK8sClient(reconciler.Client)
+			setSoftDeleteStuckThreshold(0)
 			setFinalizers(siUnstructured)
 			setFinalizers(sbUnstructured)
 			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
@@ -121,6 +186,112 @@ var _ = Describe("BTP Operator controller - deprovisioning", func() {
 			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.HardDeleting)))
 			Eventually(updateCh).Should(Receive(matchDeleted()))
 			doChecks()
+			checkIfServiceCRDsExist(true)
+		})
+
+		It("hard delete should delete CRDs when requested", func() {
+			reconciler.Client = k8sClientFromManager
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			cr.SetAnnotations(map[string]string{v1alpha1.DeleteCRDsAnnotation: "true"})
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cr)).Should(Succeed())
+			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.HardDeleting)))
+			Eventually(updateCh).Should(Receive(matchDeleted()))
+			doChecks()
+			checkIfServiceCRDsExist(false)
+		})
+	})
+
+	Describe("Deprovisioning with deletion policy", func() {
+		var siUnstructured, sbUnstructured *unstructured.Unstructured
+
+		createBtpOperatorWithDeletionPolicy := func(policy v1alpha1.DeletionPolicy) {
+			cr = createDefaultBtpOperator()
+			cr.Spec.DeletionPolicy = policy
+			Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+			Eventually(updateCh).Should(Receive(matchState(v1alpha1.StateReady)))
+
+			siUnstructured = createResource(instanceGvk, kymaNamespace, instanceName)
+			ensureResourceExists(instanceGvk)
+
+			sbUnstructured = createResource(bindingGvk, kymaNamespace, bindingName)
+			ensureResourceExists(bindingGvk)
+		}
+
+		BeforeEach(func() {
+			GinkgoWriter.Println("--- PROCESS:", GinkgoParallelProcess(), "---")
+			reconciler.Client = k8sClientFromManager
+			secret, err := createCorrectSecretFromYaml()
+			Expect(err).To(BeNil())
+			Expect(k8sClient.Patch(ctx, secret, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, sbUnstructured))).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, siUnstructured))).To(Succeed())
+			deleteSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: SecretName}, deleteSecret)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, deleteSecret)).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(kymaNamespace), backupLabelFilter)).To(Succeed())
+		})
+
+		It("Block policy should block deletion until the policy is changed to Delete", func() {
+			createBtpOperatorWithDeletionPolicy(v1alpha1.DeletionPolicyBlock)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
+			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateWarning, metav1.ConditionFalse, conditions.ServiceInstancesAndBindingsNotCleaned)))
+			ensureResourceExists(instanceGvk)
+			ensureResourceExists(bindingGvk)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			cr.Spec.DeletionPolicy = v1alpha1.DeletionPolicyDelete
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+			Eventually(updateCh).Should(Receive(matchDeleted()))
+			doChecks()
+		})
+
+		It("Delete policy should delete instances and bindings without the force-delete label", func() {
+			createBtpOperatorWithDeletionPolicy(v1alpha1.DeletionPolicyDelete)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
+			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.HardDeleting)))
+			Eventually(updateCh).Should(Receive(matchDeleted()))
+			doChecks()
+		})
+
+		It("Orphan policy should remove module components and keep instances, bindings and CRDs", func() {
+			createBtpOperatorWithDeletionPolicy(v1alpha1.DeletionPolicyOrphan)
+			setFinalizers(siUnstructured)
+			setFinalizers(sbUnstructured)
+
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
+			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateDeleting, metav1.ConditionFalse, conditions.Orphaning)))
+			Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateWarning, metav1.ConditionFalse, conditions.ServiceResourcesKept)))
+			Eventually(updateCh).Should(Receive(matchDeleted()))
+
+			Expect(k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKey{Name: DeploymentName, Namespace: kymaNamespace}, &appsv1.Deployment{}))).To(BeTrue())
+			ensureResourceExists(instanceGvk)
+			ensureResourceExists(bindingGvk)
+			for _, gvk := range []schema.GroupVersionKind{instanceGvk, bindingGvk} {
+				exists, err := reconciler.crdExists(ctx, gvk)
+				Expect(err).To(BeNil())
+				Expect(exists).To(BeTrue())
+			}
+			Expect(getBackupEntries()).To(BeEmpty())
+			Eventually(func(g Gomega) {
+				events := &corev1.EventList{}
+				g.Expect(k8sClient.List(ctx, events, client.InNamespace(defaultNamespace))).To(Succeed())
+				g.Expect(events.Items).To(ContainElement(HaveField("Reason", serviceResourcesKeptEventReason)))
+			}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+
+			for _, resource := range []*unstructured.Unstructured{sbUnstructured, siUnstructured} {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(resource), resource)).To(Succeed())
+				resource.SetFinalizers(nil)
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			}
 		})
 	})
 })
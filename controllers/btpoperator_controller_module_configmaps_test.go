@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("BTP Operator controller - module ConfigMaps", func() {
+	var cr *v1alpha1.BtpOperator
+
+	BeforeEach(func() {
+		GinkgoWriter.Println("--- PROCESS:", GinkgoParallelProcess(), "---")
+		secret, err := createCorrectSecretFromYaml()
+		Expect(err).To(BeNil())
+		Expect(k8sClient.Patch(ctx, secret, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName))).To(Succeed())
+		cr = createDefaultBtpOperator()
+		Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+		Eventually(updateCh).Should(Receive(matchState(v1alpha1.StateReady)))
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
+		Eventually(updateCh).Should(Receive(matchDeleted()))
+		Expect(isCrNotFound()).To(BeTrue())
+
+		deleteSecret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: SecretName}, deleteSecret)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, deleteSecret)).To(Succeed())
+	})
+
+	It("should render module resources as soon as the chart values ConfigMap without labels is created and deleted", func() {
+		valuesCm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ChartValuesConfigName, Namespace: kymaNamespace},
+			Data:       map[string]string{chartValuesKey: "manager:\n  enable_limited_cache: true\n"},
+		}
+		Expect(k8sClient.Create(ctx, valuesCm)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			g.Expect(cr.Status.ValuesHash).NotTo(BeEmpty())
+		}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+
+		Expect(k8sClient.Delete(ctx, valuesCm)).To(Succeed())
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			g.Expect(cr.Status.ValuesHash).To(BeEmpty())
+		}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+	})
+
+	It("should reapply module resources as soon as a patches ConfigMap without the managed-by label is updated", func() {
+		const annotationKey = "example.com/patched"
+		patches := func(value string) map[string]string {
+			return map[string]string{"patches.yaml": fmt.Sprintf(`- patch: |
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: %s
+      namespace: %s
+      annotations:
+        %s: %q
+`, DeploymentName, kymaNamespace, annotationKey, value)}
+		}
+		deploymentAnnotation := func(g Gomega) string {
+			deployment := &appsv1.Deployment{}
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Name: DeploymentName, Namespace: kymaNamespace}, deployment)).To(Succeed())
+			return deployment.GetAnnotations()[annotationKey]
+		}
+		patchesCm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "module-resources-patches",
+				Namespace: kymaNamespace,
+				Labels:    map[string]string{v1alpha1.ModuleResourcesPatchLabelKey: "true"},
+			},
+			Data: patches("v1"),
+		}
+		Expect(k8sClient.Create(ctx, patchesCm)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, patchesCm)).To(Succeed())
+		})
+
+		Eventually(deploymentAnnotation).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Equal("v1"))
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(patchesCm), patchesCm)).To(Succeed())
+		patchesCm.Data = patches("v2")
+		Expect(k8sClient.Update(ctx, patchesCm)).To(Succeed())
+
+		Eventually(deploymentAnnotation).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Equal("v2"))
+	})
+})
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("BTP Operator controller - restoring from backup", func() {
+	var cr *v1alpha1.BtpOperator
+
+	BeforeEach(func() {
+		GinkgoWriter.Println("--- PROCESS:", GinkgoParallelProcess(), "---")
+		reconciler.Client = k8sClientFromManager
+		secret, err := createCorrectSecretFromYaml()
+		Expect(err).To(BeNil())
+		Expect(k8sClient.Patch(ctx, secret, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+		cr.SetLabels(map[string]string{forceDeleteLabelKey: "true"})
+		Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, cr)).To(Succeed())
+		Eventually(updateCh).Should(Receive(matchDeleted()))
+		deleteSecret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: SecretName}, deleteSecret)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, deleteSecret)).To(Succeed())
+		Expect(k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(kymaNamespace), backupLabelFilter)).To(Succeed())
+	})
+
+	It("should recreate instances and bindings from the backup and delete the backup", func() {
+		instance := newBackupObject(instanceGvk, kymaNamespace, instanceName)
+		Expect(unstructured.SetNestedField(instance.Object, "test-instance-id", "status", "instanceID")).To(Succeed())
+		binding := newBackupObject(bindingGvk, kymaNamespace, bindingName)
+		Expect(unstructured.SetNestedField(binding.Object, "test-binding-id", "status", "bindingID")).To(Succeed())
+		createBackupSecret(instance, binding)
+
+		cr = createDefaultBtpOperator()
+		cr.SetAnnotations(map[string]string{v1alpha1.RestoreBackupAnnotation: "true"})
+		Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+		Eventually(updateCh).Should(Receive(matchState(v1alpha1.StateReady)))
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			g.Expect(cr.Status.Restore).NotTo(BeNil())
+			g.Expect(cr.Status.Restore.Total).To(Equal(2))
+			g.Expect(cr.Status.Restore.Restored).To(Equal(2))
+			g.Expect(cr.Status.Restore.Failed).To(BeEmpty())
+		}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+
+		ensureResourceExists(instanceGvk)
+		ensureResourceExists(bindingGvk)
+		restored := &unstructured.Unstructured{}
+		restored.SetGroupVersionKind(instanceGvk)
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: kymaNamespace, Name: instanceName}, restored)).To(Succeed())
+		Expect(restored.Object).To(HaveKeyWithValue("status", HaveKeyWithValue("instanceID", "test-instance-id")))
+		Eventually(getBackupEntries).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(BeEmpty())
+	})
+
+	It("should report resources without the ID in the backup and keep the backup", func() {
+		createBackupSecret(newBackupObject(instanceGvk, kymaNamespace, instanceName))
+
+		cr = createDefaultBtpOperator()
+		cr.SetAnnotations(map[string]string{v1alpha1.RestoreBackupAnnotation: "true"})
+		Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+		Eventually(updateCh).Should(Receive(matchState(v1alpha1.StateReady)))
+
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNamespace, Name: btpOperatorName}, cr)).To(Succeed())
+			g.Expect(cr.Status.Restore).NotTo(BeNil())
+			g.Expect(cr.Status.Restore.Restored).To(Equal(0))
+			g.Expect(cr.Status.Restore.Failed).To(HaveLen(1))
+			g.Expect(cr.Status.Restore.Failed[0].Name).To(Equal(instanceName))
+		}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Succeed())
+
+		Expect(getBackupEntries()).To(HaveLen(1))
+	})
+})
+
+func newBackupObject(gvk schema.GroupVersionKind, namespace, name string) unstructured.Unstructured {
+	object := unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	if gvk.Kind == instanceGvk.Kind {
+		populateServiceInstanceFields(&object)
+	} else if gvk.Kind == bindingGvk.Kind {
+		populateServiceBindingFields(&object)
+	}
+	return object
+}
+
+func createBackupSecret(objects ...unstructured.Unstructured) {
+	entries := make(map[string]map[string]interface{})
+	for _, object := range objects {
+		entry := reconciler.toBackupEntry(object)
+		entries[reconciler.backupEntryKey(entry)] = entry
+	}
+	chunks, err := reconciler.chunkBackupEntries(entries)
+	Expect(err).To(BeNil())
+	Expect(chunks).To(HaveLen(1))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-test-0", backupNamePrefix),
+			Namespace: kymaNamespace,
+			Labels:    map[string]string{backupLabelKey: "true", backupSourceLabelKey: "test"},
+		},
+		Data: map[string][]byte{backupDataKey: chunks[0]},
+	}
+	Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+}
@@ -5,8 +5,11 @@ import (
 
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -25,7 +28,42 @@ func CacheCreator(conf *rest.Config, opts cache.Options) (cache.Cache, error) {
 		&corev1.ConfigMap{}: objSelector,
 		&admissionregistrationv1.ValidatingWebhookConfiguration{}: objSelector,
 		&admissionregistrationv1.MutatingWebhookConfiguration{}:   objSelector,
+		&apiextensionsv1.CustomResourceDefinition{}:               {Transform: stripCRDSchemas},
 	}
 
 	return cache.New(conf, opts)
 }
+
+// stripCRDSchemas drops the OpenAPI schemas from cached CRDs, because only their names and conditions are used
+func stripCRDSchemas(obj interface{}) (interface{}, error) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return obj, nil
+	}
+	for i := range crd.Spec.Versions {
+		crd.Spec.Versions[i].Schema = nil
+	}
+	return crd, nil
+}
+
+// newConfigMapsMetadataCache returns a cache of the metadata of all ConfigMaps, added to the manager. Unlike the manager's cache, it holds
+// the chart values and the patches ConfigMaps, which don't have the managed-by label, so that their changes trigger the reconciliation.
+// Their data isn't cached, they are read from the API server.
+func newConfigMapsMetadataCache(mgr ctrl.Manager) (cache.Cache, error) {
+	c, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:           mgr.GetScheme(),
+		Mapper:           mgr.GetRESTMapper(),
+		DefaultTransform: cache.TransformStripManagedFields(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while creating ConfigMaps metadata cache: %w", err)
+	}
+	if err := mgr.Add(c); err != nil {
+		return nil, fmt.Errorf("while adding ConfigMaps metadata cache to the manager: %w", err)
+	}
+	return c, nil
+}
+
+func configMapMetadata() *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: configMapKind}}
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"github.com/kyma-project/btp-manager/internal/manifest"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	chartOverridesFile = "overrides.yaml"
+	chartValuesKey     = "values.yaml"
+)
+
+// moduleResources are the module resources to apply with their source, the hash of the effective chart values,
+// which is empty for pre-rendered resources, and the result of patches from the patches ConfigMaps
+type moduleResources struct {
+	objects    []*unstructured.Unstructured
+	source     string
+	valuesHash string
+	patches    *patchesResult
+}
+
+// getResourcesToApply returns module resources rendered from the chart if RenderChart is set, the chart values ConfigMap exists
+// or spec.values of the CR is set, otherwise the pre-rendered ones
+func (r *BtpOperatorReconciler) getResourcesToApply(ctx context.Context, cr *v1alpha1.BtpOperator, s *corev1.Secret) (*moduleResources, error) {
+	overrides, err := r.getChartValuesOverrides(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !RenderChart && overrides == nil {
+		objects, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToApplyPath())
+		if err != nil {
+			return nil, err
+		}
+		fsys, _ := moduleResourcesFS()
+		return &moduleResources{objects: objects, source: fmt.Sprintf("%s directory", describeModulePath(fsys, r.getResourcesToApplyPath()))}, nil
+	}
+
+	objects, valuesHash, err := r.renderModuleChart(s, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleResources{objects: objects, source: fmt.Sprintf("%s chart", describeModulePath(moduleChartFS())), valuesHash: valuesHash}, nil
+}
+
+// getChartValuesOverrides deep-merges chart value overrides from the ChartValuesConfigName ConfigMap and spec.values of the CR, which
+// take precedence. It returns nil if the ConfigMap doesn't exist and spec.values isn't set.
+func (r *BtpOperatorReconciler) getChartValuesOverrides(ctx context.Context, cr *v1alpha1.BtpOperator) (map[string]interface{}, error) {
+	overrides, err := r.getChartValuesConfigMapOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cr.Spec.Values == nil || len(cr.Spec.Values.Raw) == 0 {
+		return overrides, nil
+	}
+
+	specOverrides := make(map[string]interface{})
+	if err := json.Unmarshal(cr.Spec.Values.Raw, &specOverrides); err != nil {
+		return nil, fmt.Errorf("invalid chart values in spec.values of the BtpOperator CR: %w", err)
+	}
+	if specOverrides == nil {
+		specOverrides = make(map[string]interface{})
+	}
+	if overrides == nil {
+		return specOverrides, nil
+	}
+	return chartutil.CoalesceTables(specOverrides, overrides), nil
+}
+
+// getChartValuesConfigMapOverrides reads chart value overrides from the ChartValuesConfigName ConfigMap, or returns nil if the ConfigMap
+// doesn't exist. The ConfigMap is read as unstructured, because the cache holds only ConfigMaps with the managed-by label.
+func (r *BtpOperatorReconciler) getChartValuesConfigMapOverrides(ctx context.Context) (map[string]interface{}, error) {
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(configMapKind))
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ChartNamespace, Name: ChartValuesConfigName}, cm); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("while getting chart values ConfigMap: %w", err)
+	}
+
+	data, _, err := unstructured.NestedString(cm.Object, "data", chartValuesKey)
+	if err != nil {
+		return nil, fmt.Errorf("while reading %s key of chart values ConfigMap: %w", chartValuesKey, err)
+	}
+	overrides := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(data), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid chart values in %s key of %s/%s ConfigMap: %w", chartValuesKey, ChartNamespace, ChartValuesConfigName, err)
+	}
+	if overrides == nil {
+		overrides = make(map[string]interface{})
+	}
+	return overrides, nil
+}
+
+// renderModuleChart templates the module chart with the effective chart values and the credentials and the cluster ID from
+// the required Secret, producing the same objects as the pre-rendered module resources
+func (r *BtpOperatorReconciler) renderModuleChart(s *corev1.Secret, overrides map[string]interface{}) ([]*unstructured.Unstructured, string, error) {
+	chartFS, chartPath := moduleChartFS()
+	renderer := manifest.Renderer{FS: chartFS, ChartPath: chartPath}
+	values, err := r.chartValues(&renderer, overrides)
+	if err != nil {
+		return nil, "", err
+	}
+	valuesHash, err := r.chartValuesHash(values)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := r.setChartSecretValues(values, s); err != nil {
+		return nil, "", fmt.Errorf("while setting chart values from the required Secret: %w", err)
+	}
+	manifests, err := renderer.Render(ChartNamespace, values)
+	if err != nil {
+		return nil, "", err
+	}
+
+	objs, err := r.manifestHandler.CreateObjectsFromManifests(manifests)
+	if err != nil {
+		return nil, "", fmt.Errorf("while creating objects from rendered manifests: %w", err)
+	}
+	objects, err := r.manifestHandler.ObjectsToUnstructured(objs)
+	if err != nil {
+		return nil, "", err
+	}
+	return objects, valuesHash, nil
+}
+
+// chartValues deep-merges the overrides used to pre-render the module resources, which are placed next to the chart directory,
+// the overrides from the chart values ConfigMap, and the default values of the chart, and validates the result
+func (r *BtpOperatorReconciler) chartValues(renderer *manifest.Renderer, overrides map[string]interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	overridesPath := path.Join(path.Dir(path.Clean(renderer.ChartPath)), chartOverridesFile)
+	data, err := fs.ReadFile(fsutils.OrOS(renderer.FS), overridesPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("while reading chart overrides from %s: %w", overridesPath, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("while parsing chart overrides from %s: %w", overridesPath, err)
+		}
+		if values == nil {
+			values = make(map[string]interface{})
+		}
+	}
+
+	if overrides != nil {
+		values = chartutil.CoalesceTables(overrides, values)
+	}
+	effective, err := renderer.Values(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chart values: %w", err)
+	}
+	return effective, nil
+}
+
+// chartValuesHash returns the SHA-256 hash of the values, which are marshaled to JSON with sorted keys
+func (r *BtpOperatorReconciler) chartValuesHash(values map[string]interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("while marshaling chart values: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (r *BtpOperatorReconciler) setChartSecretValues(values map[string]interface{}, s *corev1.Secret) error {
+	for _, key := range []string{"clientid", "clientsecret", "sm_url", "tokenurl"} {
+		if err := unstructured.SetNestedField(values, string(s.Data[key]), "manager", "secret", key); err != nil {
+			return err
+		}
+	}
+	if err := unstructured.SetNestedField(values, false, "manager", "secret", "b64encoded"); err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(values, string(s.Data["cluster_id"]), "cluster", "id")
+}
+
+// getModuleResourcesToDelete returns the pre-rendered module resources from the apply and delete directories and the module resources
+// applied in the last successful reconciliation, so that the resources rendered only from the chart are deleted as well
+func (r *BtpOperatorReconciler) getModuleResourcesToDelete(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	fromApply, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToApplyPath())
+	if err != nil {
+		return nil, err
+	}
+	fromDelete, err := r.createUnstructuredObjectsFromManifestsDir(r.getResourcesToDeletePath())
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := r.getResourcesSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	us := append(fromApply, fromDelete...)
+	if snapshot != nil {
+		us = append(us, snapshot.objects...)
+	}
+	return us, nil
+}
+
+// deleteResourcesRemovedSince deletes the module resources of the snapshot, which are not applied anymore, for example, because the chart
+// values changed or the resources are not rendered from the chart anymore. CRDs are kept, because deleting them would delete their custom resources.
+func (r *BtpOperatorReconciler) deleteResourcesRemovedSince(ctx context.Context, snapshot *resourcesSnapshot, applied []*unstructured.Unstructured) error {
+	if snapshot == nil {
+		return nil
+	}
+
+	current := make(map[string]bool, len(applied))
+	for _, u := range applied {
+		current[snapshotKey(u)] = true
+	}
+	removed := make([]*unstructured.Unstructured, 0)
+	for _, u := range snapshot.objects {
+		if u.GetKind() == customResourceDefinitionKind || current[snapshotKey(u)] {
+			continue
+		}
+		removed = append(removed, u)
+	}
+	return r.deleteResources(ctx, removed)
+}
+
+// storeModuleResourcesStatus records the hash of the effective chart values and the ModuleResourcesPatched condition in the CR status.
+// An empty hash removes it, and the condition is removed if there are no patches. Errors are only logged because both are informational.
+func (r *BtpOperatorReconciler) storeModuleResourcesStatus(ctx context.Context, cr *v1alpha1.BtpOperator, resources *moduleResources) {
+	logger := log.FromContext(ctx)
+	if cr.Status.State == "" {
+		return
+	}
+
+	patch := client.MergeFromWithOptions(cr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	changed := cr.Status.ValuesHash != resources.valuesHash
+	cr.Status.ValuesHash = resources.valuesHash
+	if condition := r.patchesCondition(resources.patches); condition != nil {
+		if !hasCondition(cr.Status.Conditions, condition) {
+			conditions.SetStatusCondition(&cr.Status.Conditions, *condition)
+			changed = true
+		}
+	} else if conditions.RemoveStatusCondition(&cr.Status.Conditions, conditions.ModuleResourcesPatchedType) {
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.Status().Patch(ctx, cr, patch); err != nil {
+		logger.Error(err, "while updating the chart values hash and the ModuleResourcesPatched condition")
+	}
+}
+
+func hasCondition(existing []*metav1.Condition, condition *metav1.Condition) bool {
+	for _, c := range existing {
+		if c != nil && c.Type == condition.Type && c.Status == condition.Status && c.Reason == condition.Reason && c.Message == condition.Message {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestBtpOperatorReconciler_renderModuleChart(t *testing.T) {
+	// given
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), scheme, nil, nil)
+	defaultChartPath := ChartPath
+	ChartPath = "../module-chart/chart"
+	defer func() { ChartPath = defaultChartPath }()
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"clientid":     []byte("test-clientid"),
+		"clientsecret": []byte("test-clientsecret"),
+		"sm_url":       []byte("https://sm.example.com"),
+		"tokenurl":     []byte("https://token.example.com"),
+		"cluster_id":   []byte("test-cluster-id"),
+	}}
+	findObject := func(us []*unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+		for _, u := range us {
+			if u.GetKind() == kind && u.GetName() == name {
+				return u
+			}
+		}
+		return nil
+	}
+
+	t.Run("should merge the overrides with the default values", func(t *testing.T) {
+		// given
+		overrides := map[string]interface{}{"manager": map[string]interface{}{"replica_count": 3}}
+
+		// when
+		values, err := reconciler.chartValues(&manifest.Renderer{ChartPath: ChartPath}, overrides)
+
+		// then
+		require.NoError(t, err)
+		replicaCount, _, _ := unstructured.NestedFieldNoCopy(values, "manager", "replica_count")
+		assert.EqualValues(t, 3, replicaCount)
+		priorityClassName, _, _ := unstructured.NestedString(values, "manager", "priorityClassName")
+		assert.Equal(t, "btp-manager-kyma-priority", priorityClassName)
+		tokenURLSuffix, _, _ := unstructured.NestedString(values, "manager", "secret", "tokenurlsuffix")
+		assert.Equal(t, "/oauth/token", tokenURLSuffix)
+	})
+
+	t.Run("should reject overrides which don't meet the schema", func(t *testing.T) {
+		// given
+		overrides := map[string]interface{}{"manager": map[string]interface{}{"allowed_namespaces": "ns1"}}
+
+		// when
+		_, err := reconciler.chartValues(&manifest.Renderer{ChartPath: ChartPath}, overrides)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "manager.allowed_namespaces")
+	})
+
+	t.Run("should render the same resources as the pre-rendered ones", func(t *testing.T) {
+		// given
+		preRendered, err := reconciler.createUnstructuredObjectsFromManifestsDir("../module-resources/apply")
+		require.NoError(t, err)
+
+		// when
+		rendered, valuesHash, err := reconciler.renderModuleChart(secret, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, valuesHash, 64)
+		keys := func(us []*unstructured.Unstructured) []string {
+			result := make([]string, 0, len(us))
+			for _, u := range us {
+				result = append(result, u.GetKind()+"/"+u.GetName())
+			}
+			return result
+		}
+		assert.ElementsMatch(t, keys(preRendered), keys(rendered))
+
+		operatorSecret := findObject(rendered, secretKind, btpServiceOperatorSecret)
+		require.NotNil(t, operatorSecret)
+		clientID, _, _ := unstructured.NestedString(operatorSecret.Object, "data", "clientid")
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("test-clientid")), clientID)
+		configMap := findObject(rendered, configMapKind, btpServiceOperatorConfigMap)
+		require.NotNil(t, configMap)
+		clusterID, _, _ := unstructured.NestedString(configMap.Object, "data", "CLUSTER_ID")
+		assert.Equal(t, "test-cluster-id", clusterID)
+	})
+
+	t.Run("should apply overrides to the rendered resources", func(t *testing.T) {
+		// given
+		overrides := map[string]interface{}{"manager": map[string]interface{}{
+			"enable_limited_cache": true,
+			"allow_cluster_access": false,
+			"allowed_namespaces":   []interface{}{"ns1", "ns2"},
+		}}
+		_, defaultHash, err := reconciler.renderModuleChart(secret, nil)
+		require.NoError(t, err)
+
+		// when
+		rendered, valuesHash, err := reconciler.renderModuleChart(secret, overrides)
+
+		// then
+		require.NoError(t, err)
+		assert.NotEqual(t, defaultHash, valuesHash)
+		configMap := findObject(rendered, configMapKind, btpServiceOperatorConfigMap)
+		require.NotNil(t, configMap)
+		data, _, _ := unstructured.NestedStringMap(configMap.Object, "data")
+		assert.Equal(t, "true", data["ENABLE_LIMITED_CACHE"])
+		assert.Equal(t, "ns1,ns2", data["ALLOWED_NAMESPACES"])
+	})
+
+	t.Run("should not include credentials in the values hash", func(t *testing.T) {
+		// given
+		_, valuesHash, err := reconciler.renderModuleChart(secret, nil)
+		require.NoError(t, err)
+		otherSecret := secret.DeepCopy()
+		otherSecret.Data["clientsecret"] = []byte("other-clientsecret")
+
+		// when
+		_, otherValuesHash, err := reconciler.renderModuleChart(otherSecret, nil)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, valuesHash, otherValuesHash)
+	})
+}
+
+func TestBtpOperatorReconciler_getChartValuesOverrides(t *testing.T) {
+	newConfigMap := func(values string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ChartValuesConfigName, Namespace: ChartNamespace},
+			Data:       map[string]string{chartValuesKey: values},
+		}
+	}
+
+	t.Run("should return nil without the ConfigMap", func(t *testing.T) {
+		// given
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+		// when
+		overrides, err := reconciler.getChartValuesOverrides(context.Background(), &v1alpha1.BtpOperator{})
+
+		// then
+		require.NoError(t, err)
+		assert.Nil(t, overrides)
+	})
+
+	t.Run("should read overrides from the ConfigMap", func(t *testing.T) {
+		// given
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(newConfigMap("manager:\n  enable_limited_cache: true\n")).Build(), clientgoscheme.Scheme, nil, nil)
+
+		// when
+		overrides, err := reconciler.getChartValuesOverrides(context.Background(), &v1alpha1.BtpOperator{})
+
+		// then
+		require.NoError(t, err)
+		enabled, _, _ := unstructured.NestedBool(overrides, "manager", "enable_limited_cache")
+		assert.True(t, enabled)
+	})
+
+	t.Run("should return an error for invalid YAML", func(t *testing.T) {
+		// given
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(newConfigMap("manager: [")).Build(), clientgoscheme.Scheme, nil, nil)
+
+		// when
+		_, err := reconciler.getChartValuesOverrides(context.Background(), &v1alpha1.BtpOperator{})
+
+		// then
+		assert.ErrorContains(t, err, "invalid chart values")
+	})
+
+	t.Run("should merge overrides from the CR spec over the ConfigMap", func(t *testing.T) {
+		// given
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(newConfigMap("manager:\n  enable_limited_cache: true\n  replica_count: 2\n")).Build(), clientgoscheme.Scheme, nil, nil)
+		cr := &v1alpha1.BtpOperator{Spec: v1alpha1.BtpOperatorSpec{Values: &apiextensionsv1.JSON{Raw: []byte(`{"manager":{"replica_count":3}}`)}}}
+
+		// when
+		overrides, err := reconciler.getChartValuesOverrides(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		enabled, _, _ := unstructured.NestedBool(overrides, "manager", "enable_limited_cache")
+		assert.True(t, enabled)
+		replicaCount, _, _ := unstructured.NestedFieldNoCopy(overrides, "manager", "replica_count")
+		assert.EqualValues(t, 3, replicaCount)
+	})
+
+	t.Run("should read overrides from the CR spec without the ConfigMap", func(t *testing.T) {
+		// given
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+		cr := &v1alpha1.BtpOperator{Spec: v1alpha1.BtpOperatorSpec{Values: &apiextensionsv1.JSON{Raw: []byte(`{}`)}}}
+
+		// when
+		overrides, err := reconciler.getChartValuesOverrides(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		assert.NotNil(t, overrides)
+	})
+}
+
+func TestBtpOperatorReconciler_deleteResourcesRemovedSince(t *testing.T) {
+	// given
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	newObject := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		if kind != customResourceDefinitionKind {
+			u.SetNamespace(ChartNamespace)
+		}
+		return u
+	}
+	kept := newObject("v1", configMapKind, "kept")
+	renderedOnly := newObject("v1", configMapKind, "rendered-only")
+	crd := newObject("apiextensions.k8s.io/v1", customResourceDefinitionKind, "renderedonlies.services.cloud.sap.com")
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(kept.DeepCopy(), renderedOnly.DeepCopy(), crd.DeepCopy()).Build(), scheme, nil, nil)
+	snapshot := &resourcesSnapshot{chartVersion: "1.0.0", objects: []*unstructured.Unstructured{kept, renderedOnly, crd}}
+
+	// when
+	err := reconciler.deleteResourcesRemovedSince(context.Background(), snapshot, []*unstructured.Unstructured{kept})
+
+	// then
+	require.NoError(t, err)
+	assert.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(kept), &corev1.ConfigMap{}))
+	assert.True(t, k8serrors.IsNotFound(reconciler.Get(context.Background(), client.ObjectKeyFromObject(renderedOnly), &corev1.ConfigMap{})))
+	assert.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(crd), &apiextensionsv1.CustomResourceDefinition{}))
+}
+
+func TestBtpOperatorReconciler_getModuleResourcesToDelete(t *testing.T) {
+	// given
+	defaultResourcesPath := ResourcesPath
+	ResourcesPath = "../module-resources"
+	defer func() { ResourcesPath = defaultResourcesPath }()
+	renderedOnly := &unstructured.Unstructured{}
+	renderedOnly.SetAPIVersion("cert-manager.io/v1")
+	renderedOnly.SetKind("Certificate")
+	renderedOnly.SetName("rendered-only")
+	renderedOnly.SetNamespace(ChartNamespace)
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		// the fake client doesn't support server-side apply
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			return c.Create(ctx, obj)
+		},
+	}).Build(), clientgoscheme.Scheme, nil, nil)
+	require.NoError(t, reconciler.storeResourcesSnapshot(context.Background(), &resourcesSnapshot{chartVersion: "1.0.0", objects: []*unstructured.Unstructured{renderedOnly}}))
+
+	// when
+	resources, err := reconciler.getModuleResourcesToDelete(context.Background())
+
+	// then
+	require.NoError(t, err)
+	assert.Contains(t, resources, renderedOnly)
+	assert.Greater(t, len(resources), 1)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	serviceResourcesKeptEventReason = string(conditions.ServiceResourcesKept)
+	maxReportedResources            = 10
+)
+
+// isCRDsDeletionRequested returns true if the user opted in to deleting CRDs with the module, the Orphan deletion policy always keeps them
+func (r *BtpOperatorReconciler) isCRDsDeletionRequested(cr *v1alpha1.BtpOperator) bool {
+	return cr.GetAnnotations()[v1alpha1.DeleteCRDsAnnotation] == "true" && r.deletionPolicy(cr) != v1alpha1.DeletionPolicyOrphan
+}
+
+// keptKinds returns kinds of module resources that are not deleted during deprovisioning
+func (r *BtpOperatorReconciler) keptKinds(cr *v1alpha1.BtpOperator) []string {
+	if r.isCRDsDeletionRequested(cr) {
+		return nil
+	}
+	return []string{customResourceDefinitionKind}
+}
+
+// reportKeptServiceResources warns about ServiceInstances and ServiceBindings left in the cluster because their CRDs are kept.
+// The warning is set in the CR status before the finalizers are removed, so it's visible until the next reconciliation deletes the CR.
+// The list in the message is bounded and the full numbers per namespace are stored in the deprovisioning status.
+// It returns true if the warning was set.
+func (r *BtpOperatorReconciler) reportKeptServiceResources(ctx context.Context, cr *v1alpha1.BtpOperator) (bool, error) {
+	logger := log.FromContext(ctx)
+	if r.isCRDsDeletionRequested(cr) {
+		return false, nil
+	}
+
+	kept := make([]string, 0)
+	for _, gvk := range []schema.GroupVersionKind{r.serviceInstanceGvk(), r.serviceBindingGvk()} {
+		items, err := r.listResourcesInAllNamespaces(ctx, gvk)
+		if err != nil {
+			return false, fmt.Errorf("while listing kept %ss: %w", gvk.Kind, err)
+		}
+		for _, item := range items {
+			kept = append(kept, fmt.Sprintf("%s %s/%s", item.GetKind(), item.GetNamespace(), item.GetName()))
+		}
+	}
+	if len(kept) == 0 {
+		return false, nil
+	}
+
+	r.updateDeprovisioningProgress(ctx, cr, nil)
+
+	listed := kept
+	if len(listed) > maxReportedResources {
+		listed = listed[:maxReportedResources]
+	}
+	message := fmt.Sprintf("CRDs are kept and %d Service Instance(s) and Service Binding(s) remain in the cluster: %s", len(kept), strings.Join(listed, ", "))
+	if len(kept) > len(listed) {
+		message += fmt.Sprintf(" and %d more", len(kept)-len(listed))
+	}
+	logger.Info(message)
+	r.recorder.Event(cr, corev1.EventTypeWarning, serviceResourcesKeptEventReason, message)
+
+	if err := r.UpdateBtpOperatorStatus(ctx, cr, v1alpha1.StateWarning, conditions.ServiceResourcesKept, message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBtpOperatorReconciler_reportKeptServiceResources(t *testing.T) {
+	// given
+	defer func(interval time.Duration) { StatusUpdateCheckInterval = interval }(StatusUpdateCheckInterval)
+	StatusUpdateCheckInterval = time.Millisecond
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	newReconciler := func(cr *v1alpha1.BtpOperator) (*BtpOperatorReconciler, *record.FakeRecorder) {
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			cr,
+			&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName(bindingGvk)}},
+			&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName(instanceGvk)}},
+			newServiceResource(instanceGvk, "ns1", "instance"),
+		).WithStatusSubresource(cr).Build(), scheme, &instanceBindingServiceStub{}, nil)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.recorder = recorder
+		return reconciler, recorder
+	}
+	newDeletedCr := func() *v1alpha1.BtpOperator {
+		now := metav1.Now()
+		return &v1alpha1.BtpOperator{ObjectMeta: metav1.ObjectMeta{
+			Name:              btpOperatorName,
+			Namespace:         ChartNamespace,
+			Finalizers:        []string{deletionFinalizer},
+			DeletionTimestamp: &now,
+		}}
+	}
+
+	t.Run("should set the warning on the CR before its finalizers are removed", func(t *testing.T) {
+		// given
+		reconciler, recorder := newReconciler(newDeletedCr())
+		cr := &v1alpha1.BtpOperator{}
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: btpOperatorName, Namespace: ChartNamespace}, cr))
+
+		// when
+		reported, err := reconciler.reportKeptServiceResources(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, reported)
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(cr), cr))
+		assert.Equal(t, v1alpha1.StateWarning, cr.Status.State)
+		assert.True(t, cr.IsReasonStringEqual(string(conditions.ServiceResourcesKept)))
+		assert.Contains(t, cr.Status.Conditions[0].Message, "ServiceInstance ns1/instance")
+		assert.Equal(t, []string{deletionFinalizer}, cr.GetFinalizers())
+		assert.Len(t, recorder.Events, 1)
+
+		// when
+		err = reconciler.handleDeleting(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		err = reconciler.Get(context.Background(), client.ObjectKeyFromObject(cr), cr)
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+
+	t.Run("should not report resources if CRDs deletion is requested", func(t *testing.T) {
+		// given
+		cr := newDeletedCr()
+		cr.SetAnnotations(map[string]string{v1alpha1.DeleteCRDsAnnotation: "true"})
+		reconciler, recorder := newReconciler(cr)
+
+		// when
+		reported, err := reconciler.reportKeptServiceResources(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, reported)
+		assert.Empty(t, recorder.Events)
+	})
+}
+
+type instanceBindingServiceStub struct{}
+
+func (s *instanceBindingServiceStub) DisableSISBController() {}
+
+func (s *instanceBindingServiceStub) EnableSISBController() {}
+
+func (s *instanceBindingServiceStub) SetServiceCRDs(bool, schema.GroupVersionKind, schema.GroupVersionKind) {
+}
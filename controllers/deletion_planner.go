@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type namespaceDeletionOutcome struct {
+	namespace string
+	err       error
+}
+
+// deleteServiceResourcesInNamespaces sends delete requests for resources of the given GVKs in every namespace, in the order of the GVKs.
+// Resources of a GVK are deleted only after all resources of the previous GVKs are gone from the namespace, so that ServiceBindings
+// are deleted before ServiceInstances they belong to. Delete requests are sent by at most DeletionWorkers workers, and each namespace
+// is retried independently. Namespaces waiting for their resources to be gone don't hold the workers, they are checked together,
+// so a slow or failing namespace doesn't starve the rest. The returned map contains the outcome for every namespace, nil if all
+// delete requests in the namespace succeeded and the resources of all GVKs but the last one are gone.
+func (r *BtpOperatorReconciler) deleteServiceResourcesInNamespaces(ctx context.Context, gvks []schema.GroupVersionKind, namespaces []string) map[string]error {
+	outcomes := make(map[string]error, len(namespaces))
+	if len(gvks) == 0 {
+		for _, namespace := range namespaces {
+			outcomes[namespace] = nil
+		}
+		return outcomes
+	}
+	// nextGvk holds the index of the GVK to delete next in every namespace
+	nextGvk := make(map[string]int, len(namespaces))
+	ready, waiting := namespaces, make([]string, 0)
+	for {
+		deleted := r.inNamespaces(ready, func(namespace string) error {
+			gvk := gvks[nextGvk[namespace]]
+			if err := r.deleteAllOfWithRetry(ctx, gvk, namespace); err != nil {
+				return fmt.Errorf("while deleting %ss in %s namespace: %w", gvk.Kind, namespace, err)
+			}
+			return nil
+		})
+
+		for _, namespace := range ready {
+			nextGvk[namespace]++
+			if err := deleted[namespace]; err != nil || nextGvk[namespace] == len(gvks) {
+				outcomes[namespace] = err
+				continue
+			}
+			waiting = append(waiting, namespace)
+		}
+		if len(waiting) == 0 {
+			return outcomes
+		}
+
+		var err error
+		ready, waiting, err = r.waitUntilDeletedInNamespaces(ctx, waiting, func(namespace string) schema.GroupVersionKind {
+			return gvks[nextGvk[namespace]-1]
+		})
+		if err != nil {
+			for _, namespace := range waiting {
+				gvk := gvks[nextGvk[namespace]-1]
+				outcomes[namespace] = fmt.Errorf("while waiting for %ss in %s namespace to be deleted: %w", gvk.Kind, namespace, err)
+			}
+			return outcomes
+		}
+	}
+}
+
+// inNamespaces calls the function for every namespace in parallel by at most DeletionWorkers workers and returns the errors per namespace
+func (r *BtpOperatorReconciler) inNamespaces(namespaces []string, fn func(namespace string) error) map[string]error {
+	workers := DeletionWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(namespaces) {
+		workers = len(namespaces)
+	}
+
+	namespaceCh := make(chan string)
+	outcomeCh := make(chan namespaceDeletionOutcome, len(namespaces))
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for namespace := range namespaceCh {
+				outcomeCh <- namespaceDeletionOutcome{namespace: namespace, err: fn(namespace)}
+			}
+		}()
+	}
+	for _, namespace := range namespaces {
+		namespaceCh <- namespace
+	}
+	close(namespaceCh)
+	wg.Wait()
+	close(outcomeCh)
+
+	outcomes := make(map[string]error, len(namespaces))
+	for outcome := range outcomeCh {
+		outcomes[outcome.namespace] = outcome.err
+	}
+	return outcomes
+}
+
+// waitUntilDeletedInNamespaces polls every HardDeleteCheckInterval until no resources of the GVK of a namespace are left in some
+// of the namespaces, or until the context is done. It returns the namespaces without the resources and the ones still waiting.
+func (r *BtpOperatorReconciler) waitUntilDeletedInNamespaces(ctx context.Context, namespaces []string, gvkOf func(namespace string) schema.GroupVersionKind) ([]string, []string, error) {
+	var deleted, waiting []string
+	err := wait.PollUntilContextCancel(ctx, HardDeleteCheckInterval, true, func(ctx context.Context) (bool, error) {
+		deleted, waiting = make([]string, 0), make([]string, 0)
+		for _, namespace := range namespaces {
+			gone, err := r.deletedInNamespace(ctx, gvkOf(namespace), namespace)
+			if err != nil {
+				return false, err
+			}
+			if gone {
+				deleted = append(deleted, namespace)
+			} else {
+				waiting = append(waiting, namespace)
+			}
+		}
+		return len(deleted) > 0, nil
+	})
+	if err != nil {
+		return nil, namespaces, err
+	}
+	return deleted, waiting, nil
+}
+
+func (r *BtpOperatorReconciler) deletedInNamespace(ctx context.Context, gvk schema.GroupVersionKind, namespace string) (bool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if err := r.List(ctx, list, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return len(list.Items) == 0, nil
+}
+
+// deleteAllOfWithRetry retries a failed delete request up to DeletionRetryAttempts times, doubling the backoff after each attempt
+func (r *BtpOperatorReconciler) deleteAllOfWithRetry(ctx context.Context, gvk schema.GroupVersionKind, namespace string) error {
+	logger := log.FromContext(ctx)
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+	backoff := DeletionRetryBackoff
+	for attempt := 1; ; attempt++ {
+		deleteCtx, cancel := context.WithTimeout(ctx, DeleteRequestTimeout)
+		err := r.DeleteAllOf(deleteCtx, object, client.InNamespace(namespace))
+		cancel()
+		if err == nil || k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		if attempt >= DeletionRetryAttempts {
+			return err
+		}
+
+		logger.Info("delete request failed, retrying", "kind", gvk.Kind, "namespace", namespace, "attempt", attempt, "backoff", backoff, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// failedNamespaces returns sorted namespaces with an error other than the end of the hard delete context, which only means the
+// resources are still being deleted. A timeout of a single delete request is a failure of the namespace.
+func (r *BtpOperatorReconciler) failedNamespaces(ctx context.Context, outcomes map[string]error) []string {
+	failed := make([]string, 0)
+	for namespace, err := range outcomes {
+		if err != nil && (ctx.Err() == nil || !errors.Is(err, ctx.Err())) {
+			failed = append(failed, namespace)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// namespacesToSoftDelete returns the namespaces where the hard delete failed. Without them, the hard delete failed before
+// deleting in namespaces or timed out, so it failed in all namespaces where ServiceInstances or ServiceBindings are still present.
+func (r *BtpOperatorReconciler) namespacesToSoftDelete(ctx context.Context, failedNamespaces []string) ([]string, error) {
+	if failedNamespaces != nil {
+		return failedNamespaces, nil
+	}
+	return r.namespacesWithServiceResources(ctx)
+}
+
+// namespacesWithServiceResources returns sorted namespaces where ServiceInstances or ServiceBindings are still present
+func (r *BtpOperatorReconciler) namespacesWithServiceResources(ctx context.Context) ([]string, error) {
+	namespaces := make(map[string]struct{})
+	for _, gvk := range []schema.GroupVersionKind{r.serviceBindingGvk(), r.serviceInstanceGvk()} {
+		items, err := r.listResourcesInAllNamespaces(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			namespaces[item.GetNamespace()] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		result = append(result, namespace)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const deleteAllOfErrMsg = "expected DeleteAllOf error"
+
+func TestBtpOperatorReconciler_deleteServiceResourcesInNamespaces(t *testing.T) {
+	scheme := clientgoscheme.Scheme
+	gvks := []schema.GroupVersionKind{bindingGvk, instanceGvk}
+	defer func(workers, attempts int, backoff time.Duration) {
+		DeletionWorkers, DeletionRetryAttempts, DeletionRetryBackoff = workers, attempts, backoff
+	}(DeletionWorkers, DeletionRetryAttempts, DeletionRetryBackoff)
+	DeletionRetryAttempts = 3
+	DeletionRetryBackoff = time.Millisecond
+
+	t.Run("should delete bindings before instances in every namespace", func(t *testing.T) {
+		// given
+		DeletionWorkers = 2
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().Build(), 0)
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(context.Background(), gvks, []string{"ns1", "ns2", "ns3"})
+
+		// then
+		assert.Equal(t, map[string]error{"ns1": nil, "ns2": nil, "ns3": nil}, outcomes)
+		for _, namespace := range []string{"ns1", "ns2", "ns3"} {
+			assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace(namespace))
+		}
+	})
+
+	t.Run("should not exceed the workers limit", func(t *testing.T) {
+		// given
+		DeletionWorkers = 2
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().Build(), time.Millisecond*20)
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(context.Background(), gvks, []string{"ns1", "ns2", "ns3", "ns4", "ns5"})
+
+		// then
+		assert.Len(t, outcomes, 5)
+		assert.Equal(t, 2, recorder.maxInFlight)
+	})
+
+	t.Run("should retry failed delete requests", func(t *testing.T) {
+		// given
+		DeletionWorkers = 2
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().Build(), 0)
+		recorder.failures["ns1"] = 2
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(context.Background(), gvks, []string{"ns1", "ns2"})
+
+		// then
+		assert.Equal(t, map[string]error{"ns1": nil, "ns2": nil}, outcomes)
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceBinding, btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace("ns1"))
+	})
+
+	t.Run("should report failed namespace without affecting others", func(t *testing.T) {
+		// given
+		DeletionWorkers = 1
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().Build(), 0)
+		recorder.failures["ns1"] = DeletionRetryAttempts
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(context.Background(), gvks, []string{"ns1", "ns2"})
+
+		// then
+		require.Error(t, outcomes["ns1"])
+		assert.ErrorContains(t, outcomes["ns1"], deleteAllOfErrMsg)
+		assert.NoError(t, outcomes["ns2"])
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceBinding, btpOperatorServiceBinding}, recorder.callsInNamespace("ns1"))
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace("ns2"))
+		assert.Equal(t, []string{"ns1"}, reconciler.failedNamespaces(context.Background(), outcomes))
+	})
+}
+
+func TestBtpOperatorReconciler_deleteServiceResourcesInNamespacesWaiting(t *testing.T) {
+	scheme := clientgoscheme.Scheme
+	gvks := []schema.GroupVersionKind{bindingGvk, instanceGvk}
+	defer func(interval, backoff time.Duration, attempts, workers int) {
+		HardDeleteCheckInterval, DeletionRetryBackoff, DeletionRetryAttempts, DeletionWorkers = interval, backoff, attempts, workers
+	}(HardDeleteCheckInterval, DeletionRetryBackoff, DeletionRetryAttempts, DeletionWorkers)
+	HardDeleteCheckInterval = time.Millisecond * 10
+	DeletionWorkers = 1
+
+	t.Run("should not delete instances until bindings are gone", func(t *testing.T) {
+		// given
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().WithObjects(newServiceResource(bindingGvk, "ns1", "binding")).Build(), 0)
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(ctx, gvks, []string{"ns1"})
+
+		// then
+		require.Error(t, outcomes["ns1"])
+		assert.ErrorIs(t, outcomes["ns1"], context.DeadlineExceeded)
+		assert.Equal(t, []string{btpOperatorServiceBinding}, recorder.callsInNamespace("ns1"))
+		assert.Empty(t, reconciler.failedNamespaces(ctx, outcomes))
+	})
+
+	t.Run("should delete instances after bindings are gone", func(t *testing.T) {
+		// given
+		binding := newServiceResource(bindingGvk, "ns1", "binding")
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().WithObjects(binding).Build(), 0)
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			_ = recorder.Client.Delete(context.Background(), binding)
+		}()
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(context.Background(), gvks, []string{"ns1"})
+
+		// then
+		assert.NoError(t, outcomes["ns1"])
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace("ns1"))
+	})
+
+	t.Run("should delete in other namespaces while a namespace waits for its bindings", func(t *testing.T) {
+		// given
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().WithObjects(newServiceResource(bindingGvk, "ns1", "binding")).Build(), 0)
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+
+		// when
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(ctx, gvks, []string{"ns1", "ns2", "ns3"})
+
+		// then
+		assert.ErrorIs(t, outcomes["ns1"], context.DeadlineExceeded)
+		assert.NoError(t, outcomes["ns2"])
+		assert.NoError(t, outcomes["ns3"])
+		assert.Equal(t, []string{btpOperatorServiceBinding}, recorder.callsInNamespace("ns1"))
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace("ns2"))
+		assert.Equal(t, []string{btpOperatorServiceBinding, btpOperatorServiceInstance}, recorder.callsInNamespace("ns3"))
+	})
+
+	t.Run("should stop retrying when the hard delete deadline is reached", func(t *testing.T) {
+		// given
+		DeletionRetryAttempts, DeletionRetryBackoff = 10, time.Millisecond*40
+		recorder := newDeleteAllOfRecorder(fake.NewClientBuilder().Build(), 0)
+		recorder.failures["ns1"] = DeletionRetryAttempts
+		reconciler := NewBtpOperatorReconciler(recorder, scheme, nil, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+
+		// when
+		start := time.Now()
+		outcomes := reconciler.deleteServiceResourcesInNamespaces(ctx, gvks, []string{"ns1"})
+
+		// then
+		require.Error(t, outcomes["ns1"])
+		assert.ErrorIs(t, outcomes["ns1"], context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Millisecond*500)
+		assert.Len(t, recorder.callsInNamespace("ns1"), 2)
+	})
+}
+
+func TestBtpOperatorReconciler_namespacesToSoftDelete(t *testing.T) {
+	// given
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName(bindingGvk)}},
+		&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName(instanceGvk)}},
+		newServiceResource(bindingGvk, "ns2", "binding"),
+		newServiceResource(instanceGvk, "ns3", "instance"),
+	).Build(), scheme, nil, nil)
+
+	// when
+	onlyFailed, err := reconciler.namespacesToSoftDelete(context.Background(), []string{"ns1"})
+	require.NoError(t, err)
+	all, err := reconciler.namespacesToSoftDelete(context.Background(), nil)
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, []string{"ns1"}, onlyFailed)
+	assert.Equal(t, []string{"ns2", "ns3"}, all)
+}
+
+func newServiceResource(gvk schema.GroupVersionKind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestBtpOperatorReconciler_failedNamespaces(t *testing.T) {
+	// given
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+	outcomes := map[string]error{
+		"ns1": nil,
+		"ns2": fmt.Errorf("while deleting: %w", context.DeadlineExceeded),
+		"ns3": errors.New(deleteAllOfErrMsg),
+		"ns0": errors.New(deleteAllOfErrMsg),
+	}
+	expiredCtx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	// when
+	failedWithinDeadline := reconciler.failedNamespaces(context.Background(), outcomes)
+	failedAfterDeadline := reconciler.failedNamespaces(expiredCtx, outcomes)
+
+	// then
+	assert.Equal(t, []string{"ns0", "ns2", "ns3"}, failedWithinDeadline, "a timed out delete request is a failure")
+	assert.Equal(t, []string{"ns0", "ns3"}, failedAfterDeadline)
+}
+
+type deleteAllOfRecorder struct {
+	client.Client
+	delay       time.Duration
+	mu          sync.Mutex
+	calls       map[string][]string
+	failures    map[string]int
+	inFlight    int
+	maxInFlight int
+}
+
+func newDeleteAllOfRecorder(c client.Client, delay time.Duration) *deleteAllOfRecorder {
+	return &deleteAllOfRecorder{
+		Client:   c,
+		delay:    delay,
+		calls:    make(map[string][]string),
+		failures: make(map[string]int),
+	}
+}
+
+func (c *deleteAllOfRecorder) DeleteAllOf(_ context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	options := &client.DeleteAllOfOptions{}
+	options.ApplyOptions(opts)
+	namespace := options.Namespace
+
+	c.mu.Lock()
+	c.calls[namespace] = append(c.calls[namespace], obj.GetObjectKind().GroupVersionKind().Kind)
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	fail := c.failures[namespace] > 0
+	if fail {
+		c.failures[namespace]--
+	}
+	c.mu.Unlock()
+
+	time.Sleep(c.delay)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+
+	if fail {
+		return errors.New(deleteAllOfErrMsg)
+	}
+	return nil
+}
+
+func (c *deleteAllOfRecorder) callsInNamespace(namespace string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[namespace]
+}
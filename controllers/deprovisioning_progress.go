@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// sap-btp-service-operator sets the Failed condition to True when an operation, e.g. deletion, fails
+const serviceResourceFailedConditionType = "Failed"
+
+// collectDeprovisioningProgress counts remaining ServiceInstances and ServiceBindings per namespace
+// and finds the ones whose deletion is blocked by an error reported by sap-btp-service-operator.
+// Namespaces with a failed delete request are reported with the error even if no resources are left.
+func (r *BtpOperatorReconciler) collectDeprovisioningProgress(ctx context.Context, outcomes map[string]error) (*v1alpha1.DeprovisioningStatus, error) {
+	counts := make(map[string]*v1alpha1.NamespaceDeprovisioningStatus)
+	progress := &v1alpha1.DeprovisioningStatus{}
+	for namespace, err := range outcomes {
+		if err != nil {
+			counts[namespace] = &v1alpha1.NamespaceDeprovisioningStatus{Namespace: namespace, LastError: err.Error()}
+		}
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{r.serviceBindingGvk(), r.serviceInstanceGvk()} {
+		items, err := r.listResourcesInAllNamespaces(ctx, gvk)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			namespaceStatus, exists := counts[item.GetNamespace()]
+			if !exists {
+				namespaceStatus = &v1alpha1.NamespaceDeprovisioningStatus{Namespace: item.GetNamespace()}
+				counts[item.GetNamespace()] = namespaceStatus
+			}
+			if gvk.Kind == btpOperatorServiceBinding {
+				namespaceStatus.ServiceBindings++
+			} else {
+				namespaceStatus.ServiceInstances++
+			}
+
+			if message, stuck := r.isStuckInDeletion(item); stuck {
+				progress.StuckResources = append(progress.StuckResources, v1alpha1.StuckResource{
+					Kind:      item.GetKind(),
+					Namespace: item.GetNamespace(),
+					Name:      item.GetName(),
+					Message:   message,
+				})
+			}
+		}
+	}
+
+	for _, namespaceStatus := range counts {
+		progress.Namespaces = append(progress.Namespaces, *namespaceStatus)
+	}
+	sort.Slice(progress.Namespaces, func(i, j int) bool {
+		return progress.Namespaces[i].Namespace < progress.Namespaces[j].Namespace
+	})
+
+	return progress, nil
+}
+
+// isStuckInDeletion returns the last error message if the resource is in deletion, still has finalizers
+// and sap-btp-service-operator reports a failure in its status
+func (r *BtpOperatorReconciler) isStuckInDeletion(item unstructured.Unstructured) (string, bool) {
+	if item.GetDeletionTimestamp().IsZero() || len(item.GetFinalizers()) == 0 {
+		return "", false
+	}
+	return r.failureMessage(item)
+}
+
+// failureMessage returns the message of the condition in which sap-btp-service-operator reports a failed operation
+func (r *BtpOperatorReconciler) failureMessage(item unstructured.Unstructured) (string, bool) {
+	conditions, found, err := unstructured.NestedSlice(item.Object, "status", "conditions")
+	if err != nil || !found {
+		return "", false
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		if (conditionType == serviceResourceFailedConditionType && status == string(metav1.ConditionTrue)) ||
+			(status == string(metav1.ConditionFalse) && strings.HasSuffix(reason, "Failed")) {
+			return message, true
+		}
+	}
+
+	return "", false
+}
+
+// updateDeprovisioningProgress stores the current deprovisioning progress in the BtpOperator status.
+// Errors are only logged because the progress is informational and must not interrupt deprovisioning.
+func (r *BtpOperatorReconciler) updateDeprovisioningProgress(ctx context.Context, cr *v1alpha1.BtpOperator, outcomes map[string]error) {
+	logger := log.FromContext(ctx)
+	if ctx.Err() != nil {
+		// the hard delete timed out and the soft delete owns the status
+		return
+	}
+
+	progress, err := r.collectDeprovisioningProgress(ctx, outcomes)
+	if err != nil {
+		logger.Error(err, "while collecting deprovisioning progress")
+		return
+	}
+	r.storeDeprovisioningProgress(ctx, cr, progress)
+}
+
+// storeDeprovisioningProgress writes the progress to a freshly read BtpOperator, so the given CR is not modified.
+// The reported unresolved binding Secrets are kept.
+func (r *BtpOperatorReconciler) storeDeprovisioningProgress(ctx context.Context, cr *v1alpha1.BtpOperator, progress *v1alpha1.DeprovisioningStatus) {
+	logger := log.FromContext(ctx)
+
+	current := &v1alpha1.BtpOperator{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(cr), current); err != nil {
+		logger.Error(err, "while getting BtpOperator to update deprovisioning progress")
+		return
+	}
+	if current.Status.Deprovisioning != nil {
+		progress.UnresolvedBindingSecrets = mergeUnresolvedBindingSecrets(current.Status.Deprovisioning.UnresolvedBindingSecrets, progress.UnresolvedBindingSecrets)
+	}
+	progress.LastUpdateTime = metav1.Now()
+	current.Status.Deprovisioning = progress
+	if err := r.Status().Update(ctx, current); err != nil {
+		logger.Error(err, "while updating deprovisioning progress")
+		return
+	}
+	logger.Info("deprovisioning progress updated", "namespaces", len(progress.Namespaces), "stuck", len(progress.StuckResources))
+}
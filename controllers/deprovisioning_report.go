@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	deprovisioningReportName = "btp-manager-deprovisioning-report"
+	deprovisioningReportKey  = "report.yaml"
+)
+
+// DeprovisioningReport lists resources that deprovisioning of the module would remove at the time of generation
+type DeprovisioningReport struct {
+	GeneratedAt                   string              `yaml:"generatedAt"`
+	BtpOperator                   string              `yaml:"btpOperator"`
+	ForceDelete                   bool                `yaml:"forceDelete"`
+	DeletionPolicy                string              `yaml:"deletionPolicy"`
+	Blocked                       bool                `yaml:"blocked"`
+	ServiceInstances              map[string][]string `yaml:"serviceInstances"`
+	ServiceBindings               map[string][]string `yaml:"serviceBindings"`
+	BindingSecrets                map[string][]string `yaml:"bindingSecrets"`
+	KeepBindingSecrets            bool                `yaml:"keepBindingSecrets"`
+	ModuleResources               []ReportedResource  `yaml:"moduleResources"`
+	Webhooks                      []ReportedResource  `yaml:"webhooks"`
+	CustomResourceDefinitions     []string            `yaml:"customResourceDefinitions"`
+	KeepCustomResourceDefinitions bool                `yaml:"keepCustomResourceDefinitions"`
+}
+
+type ReportedResource struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	Namespace  string `yaml:"namespace,omitempty"`
+}
+
+func (r *BtpOperatorReconciler) isDeprovisioningDryRun(cr *v1alpha1.BtpOperator) bool {
+	return cr.ObjectMeta.DeletionTimestamp.IsZero() && cr.GetAnnotations()[v1alpha1.DeprovisioningDryRunAnnotation] == "true"
+}
+
+func (r *BtpOperatorReconciler) handleDeprovisioningDryRun(ctx context.Context, cr *v1alpha1.BtpOperator) error {
+	logger := log.FromContext(ctx)
+	logger.Info("generating deprovisioning report")
+
+	report, err := r.generateDeprovisioningReport(ctx, cr)
+	if err != nil {
+		return fmt.Errorf("while generating deprovisioning report: %w", err)
+	}
+	if err := r.storeDeprovisioningReport(ctx, report); err != nil {
+		return fmt.Errorf("while storing deprovisioning report: %w", err)
+	}
+
+	logger.Info("deprovisioning report stored", "name", deprovisioningReportName, "namespace", ChartNamespace)
+	return nil
+}
+
+func (r *BtpOperatorReconciler) generateDeprovisioningReport(ctx context.Context, cr *v1alpha1.BtpOperator) (*DeprovisioningReport, error) {
+	report := &DeprovisioningReport{
+		GeneratedAt:                   time.Now().UTC().Format(time.RFC3339),
+		BtpOperator:                   fmt.Sprintf("%s/%s", cr.GetNamespace(), cr.GetName()),
+		ForceDelete:                   r.IsForceDelete(cr),
+		DeletionPolicy:                string(r.deletionPolicy(cr)),
+		KeepCustomResourceDefinitions: !r.isCRDsDeletionRequested(cr),
+		KeepBindingSecrets:            SoftDeleteKeepBindingSecrets,
+		ServiceInstances:              map[string][]string{},
+		ServiceBindings:               map[string][]string{},
+		BindingSecrets:                map[string][]string{},
+	}
+
+	instances, err := r.listResourcesInAllNamespaces(ctx, r.serviceInstanceGvk())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range instances {
+		report.ServiceInstances[item.GetNamespace()] = append(report.ServiceInstances[item.GetNamespace()], item.GetName())
+	}
+
+	bindings, err := r.listResourcesInAllNamespaces(ctx, r.serviceBindingGvk())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range bindings {
+		report.ServiceBindings[item.GetNamespace()] = append(report.ServiceBindings[item.GetNamespace()], item.GetName())
+		if SoftDeleteKeepBindingSecrets {
+			continue
+		}
+		secret, _, err := r.getBindingSecret(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		if secret != nil {
+			report.BindingSecrets[item.GetNamespace()] = append(report.BindingSecrets[item.GetNamespace()], secret.GetName())
+		}
+	}
+	report.Blocked = r.deletionPolicy(cr) == v1alpha1.DeletionPolicyBlock && (len(instances) > 0 || len(bindings) > 0)
+
+	moduleResources, err := r.listExistingModuleResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range moduleResources {
+		switch u.GetKind() {
+		case MutatingWebhookConfiguration, ValidatingWebhookConfiguration:
+			report.Webhooks = append(report.Webhooks, r.toReportedResource(u))
+		case customResourceDefinitionKind:
+			report.CustomResourceDefinitions = append(report.CustomResourceDefinitions, u.GetName())
+		default:
+			report.ModuleResources = append(report.ModuleResources, r.toReportedResource(u))
+		}
+	}
+
+	return report, nil
+}
+
+func (r *BtpOperatorReconciler) listResourcesInAllNamespaces(ctx context.Context, gvk schema.GroupVersionKind) ([]unstructured.Unstructured, error) {
+	exists, err := r.crdExists(ctx, gvk)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	list := r.GvkToList(gvk)
+	if err := r.List(ctx, list, client.InNamespace(corev1.NamespaceAll)); err != nil {
+		return nil, fmt.Errorf("while listing %s: %w", gvk.Kind, err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].GetNamespace() != list.Items[j].GetNamespace() {
+			return list.Items[i].GetNamespace() < list.Items[j].GetNamespace()
+		}
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+	return list.Items, nil
+}
+
+// listExistingModuleResources lists resources that deleteBtpOperatorResources would remove
+func (r *BtpOperatorReconciler) listExistingModuleResources(ctx context.Context) ([]unstructured.Unstructured, error) {
+	resources, err := r.getModuleResourcesToDelete(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create objects from manifests: %w", err)
+	}
+
+	existing := make([]unstructured.Unstructured, 0)
+	listedGvks := make(map[string]struct{}, 0)
+	for _, u := range resources {
+		gvk := u.GroupVersionKind()
+		if _, listed := listedGvks[gvk.String()]; listed {
+			continue
+		}
+		listedGvks[gvk.String()] = struct{}{}
+
+		list := r.GvkToList(gvk)
+		if err := r.List(ctx, list, client.InNamespace(ChartNamespace), managedByLabelFilter); err != nil {
+			if k8serrors.IsNotFound(err) || k8serrors.IsMethodNotSupported(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("while listing %s module resources: %w", gvk.Kind, err)
+		}
+		for _, item := range list.Items {
+			if item.GetKind() == configMapKind && item.GetName() == deprovisioningReportName {
+				continue
+			}
+			existing = append(existing, item)
+		}
+	}
+
+	return existing, nil
+}
+
+func (r *BtpOperatorReconciler) toReportedResource(u unstructured.Unstructured) ReportedResource {
+	return ReportedResource{
+		ApiVersion: u.GetAPIVersion(),
+		Kind:       u.GetKind(),
+		Name:       u.GetName(),
+		Namespace:  u.GetNamespace(),
+	}
+}
+
+// storeDeprovisioningReport writes the report only if its content differs from the stored report regardless of the generation time,
+// because every write of the ConfigMap with the managed-by label triggers the reconciliation, which generates the report again
+func (r *BtpOperatorReconciler) storeDeprovisioningReport(ctx context.Context, report *DeprovisioningReport) error {
+	out, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	stored := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Name: deprovisioningReportName, Namespace: ChartNamespace}, stored); err == nil {
+		previous := &DeprovisioningReport{}
+		if err := yaml.Unmarshal([]byte(stored.Data[deprovisioningReportKey]), previous); err == nil {
+			previous.GeneratedAt = report.GeneratedAt
+			if previousOut, err := yaml.Marshal(previous); err == nil && string(previousOut) == string(out) {
+				return nil
+			}
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       configMapKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deprovisioningReportName,
+			Namespace: ChartNamespace,
+			Labels:    map[string]string{managedByLabelKey: operatorName},
+		},
+		Data: map[string]string{deprovisioningReportKey: string(out)},
+	}
+
+	return r.Patch(ctx, cm, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName))
+}
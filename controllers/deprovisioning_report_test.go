@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestBtpOperatorReconciler_storeDeprovisioningReport(t *testing.T) {
+	// given
+	patches := 0
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patches++
+			err := c.Create(ctx, obj.(*corev1.ConfigMap).DeepCopy())
+			if k8serrors.IsAlreadyExists(err) {
+				return c.Update(ctx, obj.(*corev1.ConfigMap).DeepCopy())
+			}
+			return err
+		},
+	}).Build(), clientgoscheme.Scheme, nil, nil)
+	newReport := func(generatedAt string, instances ...string) *DeprovisioningReport {
+		return &DeprovisioningReport{
+			GeneratedAt:      generatedAt,
+			BtpOperator:      "kyma-system/btpoperator",
+			ServiceInstances: map[string][]string{"ns1": instances},
+			ServiceBindings:  map[string][]string{},
+		}
+	}
+
+	// when
+	require.NoError(t, reconciler.storeDeprovisioningReport(context.Background(), newReport("2024-01-01T00:00:00Z", "instance-1")))
+
+	// then
+	assert.Equal(t, 1, patches)
+
+	// when
+	require.NoError(t, reconciler.storeDeprovisioningReport(context.Background(), newReport("2024-01-01T00:01:00Z", "instance-1")))
+
+	// then
+	assert.Equal(t, 1, patches, "the report with the same content isn't written again")
+
+	// when
+	require.NoError(t, reconciler.storeDeprovisioningReport(context.Background(), newReport("2024-01-01T00:02:00Z", "instance-1", "instance-2")))
+
+	// then
+	assert.Equal(t, 2, patches)
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: deprovisioningReportName, Namespace: ChartNamespace}, cm))
+	assert.Contains(t, cm.Data[deprovisioningReportKey], "2024-01-01T00:02:00Z")
+}
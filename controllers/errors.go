@@ -1,6 +1,11 @@
 package controllers
 
-import "github.com/kyma-project/btp-manager/internal/conditions"
+import (
+	"errors"
+
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/kyma-project/btp-manager/internal/manifest"
+)
 
 type ErrorWithReason struct {
 	message string
@@ -17,3 +22,20 @@ func NewErrorWithReason(reason conditions.Reason, message string) *ErrorWithReas
 func (e *ErrorWithReason) Error() string {
 	return e.message
 }
+
+// reasonForError returns the reason specific to the error, or the given reason if there is none
+func reasonForError(err error, reason conditions.Reason) conditions.Reason {
+	var rolledBackErr *upgradeRolledBackError
+	if errors.As(err, &rolledBackErr) {
+		return conditions.UpgradeRolledBack
+	}
+	var verificationErr *manifest.VerificationError
+	if errors.As(err, &verificationErr) {
+		return conditions.ManifestVerificationFailed
+	}
+	var validationErr *manifestValidationError
+	if errors.As(err, &validationErr) {
+		return conditions.ManifestValidationFailed
+	}
+	return reason
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var imageDigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// podSpecPaths are the paths of the pod specs in the objects of kinds with pod templates
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+var containerFields = []string{"initContainers", "containers", "ephemeralContainers"}
+
+// registryMirror replaces the prefix of images, which ends at a path component, the tag, or the digest
+type registryMirror struct {
+	from string
+	to   string
+}
+
+// imageRewriter rewrites images of module resources for clusters, which pull images from a mirror registry
+type imageRewriter struct {
+	// mirrors are sorted from the longest prefix, so the most specific one is used
+	mirrors []registryMirror
+	// digests map repositories of the images in module resources to the digests to pin
+	digests     map[string]string
+	pullSecrets []string
+}
+
+// newImageRewriter parses the ImageRegistryMirrors, ImageDigests, and ImagePullSecrets knobs
+func newImageRewriter() (*imageRewriter, error) {
+	mirrors, err := parseRegistryMirrors(ImageRegistryMirrors)
+	if err != nil {
+		return nil, err
+	}
+	digests, err := parseImageDigests(ImageDigests)
+	if err != nil {
+		return nil, err
+	}
+	return &imageRewriter{mirrors: mirrors, digests: digests, pullSecrets: parseImagePullSecrets(ImagePullSecrets)}, nil
+}
+
+// parseRegistryMirrors parses comma-separated "<prefix>=<mirror prefix>" pairs
+func parseRegistryMirrors(value string) ([]registryMirror, error) {
+	pairs, err := parseKeyValuePairs(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry mirrors: %w", err)
+	}
+	mirrors := make([]registryMirror, 0, len(pairs))
+	for _, pair := range pairs {
+		mirrors = append(mirrors, registryMirror{from: strings.TrimSuffix(pair[0], "/"), to: strings.TrimSuffix(pair[1], "/")})
+	}
+	sort.SliceStable(mirrors, func(i, j int) bool { return len(mirrors[i].from) > len(mirrors[j].from) })
+	return mirrors, nil
+}
+
+// parseImageDigests parses comma-separated "<repository>=<digest>" pairs
+func parseImageDigests(value string) (map[string]string, error) {
+	pairs, err := parseKeyValuePairs(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image digests: %w", err)
+	}
+	digests := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		if !imageDigestPattern.MatchString(pair[1]) {
+			return nil, fmt.Errorf("invalid image digests: %s digest of %s is not a sha256 digest", pair[1], pair[0])
+		}
+		digests[pair[0]] = pair[1]
+	}
+	return digests, nil
+}
+
+// parseImagePullSecrets parses comma-separated Secret names
+func parseImagePullSecrets(value string) []string {
+	secrets := make([]string, 0)
+	for _, secret := range strings.Split(value, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+func parseKeyValuePairs(value string) ([][2]string, error) {
+	pairs := make([][2]string, 0)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, val, found := strings.Cut(entry, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !found || key == "" || val == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", entry)
+		}
+		pairs = append(pairs, [2]string{key, val})
+	}
+	return pairs, nil
+}
+
+// rewriteImages rewrites images of all containers in the module resources and adds the image pull Secrets to their pod specs
+func (r *BtpOperatorReconciler) rewriteImages(us []*unstructured.Unstructured) error {
+	rewriter, err := newImageRewriter()
+	if err != nil {
+		return err
+	}
+	if len(rewriter.mirrors) == 0 && len(rewriter.digests) == 0 && len(rewriter.pullSecrets) == 0 {
+		return nil
+	}
+
+	for _, u := range us {
+		path, found := podSpecPaths[u.GetKind()]
+		if !found {
+			continue
+		}
+		podSpec, found, err := unstructured.NestedMap(u.Object, path...)
+		if err != nil {
+			return fmt.Errorf("while reading pod spec of %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		if !found {
+			continue
+		}
+		rewriter.rewritePodSpec(podSpec)
+		if err := unstructured.SetNestedMap(u.Object, podSpec, path...); err != nil {
+			return fmt.Errorf("while setting pod spec of %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func (ir *imageRewriter) rewritePodSpec(podSpec map[string]interface{}) {
+	for _, field := range containerFields {
+		containers, _ := podSpec[field].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, ok := container["image"].(string); ok && image != "" {
+				container["image"] = ir.rewriteImage(image)
+			}
+		}
+	}
+
+	if len(ir.pullSecrets) == 0 {
+		return
+	}
+	pullSecrets, _ := podSpec["imagePullSecrets"].([]interface{})
+	existing := make(map[string]bool, len(pullSecrets))
+	for _, s := range pullSecrets {
+		if secret, ok := s.(map[string]interface{}); ok {
+			if name, ok := secret["name"].(string); ok {
+				existing[name] = true
+			}
+		}
+	}
+	for _, name := range ir.pullSecrets {
+		if !existing[name] {
+			pullSecrets = append(pullSecrets, map[string]interface{}{"name": name})
+		}
+	}
+	podSpec["imagePullSecrets"] = pullSecrets
+}
+
+// rewriteImage pins the digest of the image repository, if it's set, and replaces the registry prefix with the mirror
+func (ir *imageRewriter) rewriteImage(image string) string {
+	repository, tag, digest := splitImage(image)
+	if pinned, found := ir.digests[repository]; found {
+		digest = pinned
+	}
+	for _, mirror := range ir.mirrors {
+		if rest, found := strings.CutPrefix(repository, mirror.from); found && (rest == "" || rest[0] == '/') {
+			repository = mirror.to + rest
+			break
+		}
+	}
+
+	image = repository
+	if tag != "" {
+		image += ":" + tag
+	}
+	if digest != "" {
+		image += "@" + digest
+	}
+	return image
+}
+
+// splitImage splits the image reference into the repository, the tag, and the digest
+func splitImage(image string) (string, string, string) {
+	repository, digest, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		return repository[:i], repository[i+1:], digest
+	}
+	return repository, "", digest
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestRewriteImages(t *testing.T) {
+	defaultMirrors, defaultDigests, defaultPullSecrets := ImageRegistryMirrors, ImageDigests, ImagePullSecrets
+	defer func() {
+		ImageRegistryMirrors, ImageDigests, ImagePullSecrets = defaultMirrors, defaultDigests, defaultPullSecrets
+	}()
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+	t.Run("should rewrite images and add image pull secrets in module resources", func(t *testing.T) {
+		// given
+		ImageRegistryMirrors = "quay.io=mirror.example.com/quay, ghcr.io=mirror.example.com/ghcr,ghcr.io/sap/sap-btp-service-operator=mirror.example.com/operator"
+		ImageDigests = "ghcr.io/sap/sap-btp-service-operator/controller=" + testDigest
+		ImagePullSecrets = "mirror-credentials,registry-credentials"
+		resources, err := reconciler.createUnstructuredObjectsFromManifestsDir("../module-resources/apply")
+		require.NoError(t, err)
+
+		// when
+		err = reconciler.rewriteImages(resources)
+
+		// then
+		require.NoError(t, err)
+		var podSpec map[string]interface{}
+		for _, u := range resources {
+			if u.GetKind() == deploymentKind {
+				podSpec, _, _ = unstructured.NestedMap(u.Object, "spec", "template", "spec")
+			}
+		}
+		require.NotNil(t, podSpec)
+		images := make([]string, 0)
+		for _, c := range podSpec["containers"].([]interface{}) {
+			images = append(images, c.(map[string]interface{})["image"].(string))
+		}
+		assert.ElementsMatch(t, []string{
+			"mirror.example.com/quay/brancz/kube-rbac-proxy:v0.15.0",
+			"mirror.example.com/operator/controller:v0.7.1@" + testDigest,
+		}, images)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"name": "mirror-credentials"},
+			map[string]interface{}{"name": "registry-credentials"},
+		}, podSpec["imagePullSecrets"])
+	})
+
+	t.Run("should rewrite images of all kinds of containers", func(t *testing.T) {
+		// given
+		ImageRegistryMirrors, ImageDigests, ImagePullSecrets = "localhost:5000=mirror.example.com", "", "existing"
+		cronJob := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"spec": map[string]interface{}{"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+				"initContainers":   []interface{}{map[string]interface{}{"image": "localhost:5000/init"}},
+				"containers":       []interface{}{map[string]interface{}{"image": "localhost:5000/job@" + testDigest}},
+				"imagePullSecrets": []interface{}{map[string]interface{}{"name": "existing"}},
+			}}}}},
+		}}
+
+		// when
+		err := reconciler.rewriteImages([]*unstructured.Unstructured{cronJob})
+
+		// then
+		require.NoError(t, err)
+		podSpec, _, _ := unstructured.NestedMap(cronJob.Object, "spec", "jobTemplate", "spec", "template", "spec")
+		assert.Equal(t, "mirror.example.com/init", podSpec["initContainers"].([]interface{})[0].(map[string]interface{})["image"])
+		assert.Equal(t, "mirror.example.com/job@"+testDigest, podSpec["containers"].([]interface{})[0].(map[string]interface{})["image"])
+		assert.Len(t, podSpec["imagePullSecrets"], 1)
+	})
+}
+
+func TestRewriteImage(t *testing.T) {
+	// given
+	mirrors, err := parseRegistryMirrors("quay.io=mirror.example.com/quay,quay.io/brancz=mirror.example.com/brancz/")
+	require.NoError(t, err)
+	rewriter := &imageRewriter{mirrors: mirrors, digests: map[string]string{"busybox": testDigest}}
+
+	for image, expected := range map[string]string{
+		"quay.io/brancz/kube-rbac-proxy:v0.15.0": "mirror.example.com/brancz/kube-rbac-proxy:v0.15.0",
+		"quay.io/other/image":                    "mirror.example.com/quay/other/image",
+		"quay.iox/image:v1":                      "quay.iox/image:v1",
+		"busybox:1.36":                           "busybox:1.36@" + testDigest,
+	} {
+		// when
+		actual := rewriter.rewriteImage(image)
+
+		// then
+		assert.Equal(t, expected, actual, image)
+	}
+}
+
+func TestParseImageRewritingConfig(t *testing.T) {
+	// when
+	_, mirrorsErr := parseRegistryMirrors("quay.io")
+	_, digestsErr := parseImageDigests("busybox=latest")
+	pullSecrets := parseImagePullSecrets(" first,, second ")
+
+	// then
+	assert.ErrorContains(t, mirrorsErr, `"quay.io" is not a key=value pair`)
+	assert.ErrorContains(t, digestsErr, "latest digest of busybox is not a sha256 digest")
+	assert.Equal(t, []string{"first", "second"}, pullSecrets)
+}
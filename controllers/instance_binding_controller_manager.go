@@ -4,90 +4,137 @@ import (
 	"context"
 	"sync"
 
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/client-go/rest"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
-// InstanceBindingControllerManager runs and stops the ServiceInstance controller
+// InstanceBindingControllerManager runs and stops the ServiceInstance controller on the main manager.
+// The ServiceInstance and ServiceBinding CRDs are installed with the module, so the controller can't be registered when the manager starts.
+// Instead, a new unmanaged controller is started when the controller is enabled and the CRDs are established, and it is stopped together
+// with the ServiceInstance and ServiceBinding informers in the manager cache when the controller is disabled or the CRDs are removed.
+// The controller is restarted when the discovered API versions of the CRDs change.
 type InstanceBindingControllerManager struct {
-	client.Client
-	*rest.Config
-	Scheme *runtime.Scheme
+	mgr        ctrl.Manager
+	reconciler *ServiceInstanceReconciler
+	ctx        context.Context
 
 	sisbControllerMu sync.Mutex
-	sisbReconciler   *ServiceInstanceReconciler
-	mgr              ctrl.Manager
-	stopper          func()
 	enabled          bool
-	cfg              *rest.Config
-	ctx              context.Context
+	crdsAvailable    bool
+	instanceGvk      schema.GroupVersionKind
+	bindingGvk       schema.GroupVersionKind
+	runningGvks      []schema.GroupVersionKind
+	stopper          func()
+	stopped          chan struct{}
 }
 
-func NewInstanceBindingControllerManager(ctx context.Context, client client.Client, scheme *runtime.Scheme, cfg *rest.Config) *InstanceBindingControllerManager {
+func NewInstanceBindingControllerManager(ctx context.Context, mgr ctrl.Manager) *InstanceBindingControllerManager {
 	return &InstanceBindingControllerManager{
-		Client: client,
-		Scheme: scheme,
-		cfg:    cfg,
-		ctx:    ctx,
+		mgr:         mgr,
+		reconciler:  NewServiceInstanceReconciler(mgr.GetClient(), mgr.GetScheme()),
+		ctx:         ctx,
+		instanceGvk: instanceGvk,
+		bindingGvk:  bindingGvk,
 	}
 }
 
 func (r *InstanceBindingControllerManager) EnableSISBController() {
 	r.sisbControllerMu.Lock()
 	defer r.sisbControllerMu.Unlock()
-	logger := log.Log
+	r.enabled = true
+	r.syncController()
+}
 
-	if r.enabled {
-		return
+func (r *InstanceBindingControllerManager) DisableSISBController() {
+	r.sisbControllerMu.Lock()
+	defer r.sisbControllerMu.Unlock()
+	r.enabled = false
+	r.syncController()
+}
+
+// SetServiceCRDs starts the enabled controller when the CRDs become established and stops it when they are removed.
+// The controller watches the given versions of the ServiceInstance and ServiceBinding APIs.
+func (r *InstanceBindingControllerManager) SetServiceCRDs(available bool, instanceGvk, bindingGvk schema.GroupVersionKind) {
+	r.sisbControllerMu.Lock()
+	defer r.sisbControllerMu.Unlock()
+	r.crdsAvailable = available
+	r.instanceGvk = instanceGvk
+	r.bindingGvk = bindingGvk
+	r.syncController()
+}
+
+// syncController starts or stops the controller, the caller must hold sisbControllerMu
+func (r *InstanceBindingControllerManager) syncController() {
+	running := r.stopper != nil
+	outdated := running && (r.runningGvks[0] != r.instanceGvk || r.runningGvks[1] != r.bindingGvk)
+	if running && (!r.enabled || !r.crdsAvailable || outdated) {
+		r.stopController()
+		running = false
 	}
-	mgr, err := ctrl.NewManager(r.cfg, ctrl.Options{
-		Scheme:                 r.Scheme,
-		Metrics:                server.Options{BindAddress: "0"},
-		HealthProbeBindAddress: "0",
-	})
-	if err != nil {
-		logger.Error(err, "unable to create controller manager")
-		return
+	if r.enabled && r.crdsAvailable && !running {
+		r.startController()
 	}
-	r.mgr = mgr
+}
 
-	if r.sisbReconciler != nil {
-		r.sisbReconciler = NewServiceInstanceReconciler(r.Client, r.Scheme)
-		err = r.sisbReconciler.SetupWithManager(r.mgr)
-		if err != nil {
-			logger.Error(err, "unable to create SI SB controller")
-			return
-		}
+func (r *InstanceBindingControllerManager) startController() {
+	logger := log.Log
+
+	c, err := r.reconciler.newController(r.mgr, r.instanceGvk, r.bindingGvk)
+	if err != nil {
+		logger.Error(err, "unable to create SI SB controller")
+		return
 	}
-	r.enabled = true
 
 	contextWithCancel, cancel := context.WithCancel(r.ctx)
+	stopped := make(chan struct{})
 	r.stopper = cancel
+	r.stopped = stopped
+	r.runningGvks = []schema.GroupVersionKind{r.instanceGvk, r.bindingGvk}
 	go func() {
-		err := r.mgr.Start(contextWithCancel)
+		err := c.Start(contextWithCancel)
+		close(stopped)
 		if err != nil {
-			logger.Error(err, "unable to start SI SB controller")
+			logger.Error(err, "SI SB controller stopped with an error")
 		} else {
-			logger.Info("SI SB controller goroutine stopped")
+			logger.Info("SI SB controller stopped")
 		}
-	}()
 
+		// the controller can stop on its own, e.g. if the informers don't sync, then it is started again by the next change
+		r.sisbControllerMu.Lock()
+		defer r.sisbControllerMu.Unlock()
+		if r.stopped == stopped {
+			cancel()
+			r.stopper = nil
+			r.stopped = nil
+			r.runningGvks = nil
+		}
+	}()
 }
 
-func (r *InstanceBindingControllerManager) DisableSISBController() {
-	r.sisbControllerMu.Lock()
-	defer r.sisbControllerMu.Unlock()
-	if !r.enabled {
-		return
-	}
+func (r *InstanceBindingControllerManager) stopController() {
+	logger := log.Log
+
+	// wait for the controller to stop, so that the next started controller doesn't run next to it
+	r.stopper()
+	<-r.stopped
+	gvks := r.runningGvks
+	r.stopper = nil
+	r.stopped = nil
+	r.runningGvks = nil
 
-	if r.stopper != nil {
-		r.stopper()
+	for _, gvk := range gvks {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := r.mgr.GetCache().RemoveInformer(r.ctx, obj); err != nil {
+			logger.Error(err, "unable to remove informer", "kind", gvk.Kind)
+		}
 	}
-	r.enabled = false
+}
 
+func (r *InstanceBindingControllerManager) isSISBControllerRunning() bool {
+	r.sisbControllerMu.Lock()
+	defer r.sisbControllerMu.Unlock()
+	return r.stopper != nil
 }
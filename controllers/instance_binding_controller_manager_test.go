@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+func TestInstanceBindingControllerManager_EnableDisable(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mgr, err := ctrl.NewManager(&rest.Config{Host: "http://127.0.0.1:0"}, ctrl.Options{
+		Scheme:                 clientgoscheme.Scheme,
+		Metrics:                server.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	require.NoError(t, err)
+	controllerManager := NewInstanceBindingControllerManager(ctx, mgr)
+
+	// when
+	controllerManager.EnableSISBController()
+
+	// then
+	assert.False(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	controllerManager.SetServiceCRDs(true, instanceGvk, bindingGvk)
+	controllerManager.EnableSISBController()
+
+	// then
+	assert.True(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	controllerManager.DisableSISBController()
+
+	// then
+	assert.False(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	controllerManager.EnableSISBController()
+
+	// then
+	assert.True(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	controllerManager.SetServiceCRDs(false, instanceGvk, bindingGvk)
+
+	// then
+	assert.False(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	controllerManager.SetServiceCRDs(true, instanceGvk, bindingGvk)
+
+	// then
+	assert.True(t, controllerManager.isSISBControllerRunning())
+
+	// when
+	instanceV2Gvk, bindingV2Gvk := instanceGvk, bindingGvk
+	instanceV2Gvk.Version, bindingV2Gvk.Version = "v2", "v2"
+	controllerManager.SetServiceCRDs(true, instanceV2Gvk, bindingV2Gvk)
+
+	// then
+	assert.True(t, controllerManager.isSISBControllerRunning())
+	assert.Equal(t, []schema.GroupVersionKind{instanceV2Gvk, bindingV2Gvk}, controllerManager.runningGvks)
+	assert.Equal(t, instanceV2Gvk, controllerManager.reconciler.instanceGvk)
+
+	// when
+	controllerManager.DisableSISBController()
+	controllerManager.DisableSISBController()
+
+	// then
+	assert.False(t, controllerManager.isSISBControllerRunning())
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// manifestValidationError is an error of module resources, which the API server would reject
+type manifestValidationError struct {
+	problems []string
+}
+
+func (e *manifestValidationError) Error() string {
+	problems := e.problems
+	if len(problems) > maxReportedResources {
+		problems = append(problems[:maxReportedResources:maxReportedResources], fmt.Sprintf("and %d more", len(e.problems)-maxReportedResources))
+	}
+	return fmt.Sprintf("%d module resource(s) would be rejected: %s", len(e.problems), strings.Join(problems, "; "))
+}
+
+// validateResources checks that the API server serves the kinds of all module resources and accepts them in a server-side
+// dry run, so that no resource is applied if any of them would be rejected. Kinds defined by CRDs among the module resources
+// aren't served before the CRDs are applied, so resources of these kinds are skipped until they are served.
+func (r *BtpOperatorReconciler) validateResources(ctx context.Context, us []*unstructured.Unstructured) error {
+	logger := log.FromContext(ctx)
+
+	definedGvks, err := r.gvksDefinedByCRDs(us)
+	if err != nil {
+		return err
+	}
+	served, err := r.servedKinds(us)
+	if err != nil {
+		return err
+	}
+
+	problems := make([]string, 0)
+	for _, u := range us {
+		gvk := u.GroupVersionKind()
+		if definedGvks[gvk] && !served[gvk] {
+			continue
+		}
+		if !served[gvk] {
+			problems = append(problems, fmt.Sprintf("%s %s: %s is not served", u.GetKind(), u.GetName(), gvk.GroupVersion()))
+			continue
+		}
+		if err := r.dryRunApplyOrUpdate(ctx, u); err != nil {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", u.GetKind(), u.GetName(), err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &manifestValidationError{problems: problems}
+	}
+	logger.Info(fmt.Sprintf("validated %d module resources", len(us)))
+	return nil
+}
+
+// dryRunApplyOrUpdate does the same request as applyOrUpdateResource for the resource, in a server-side dry run
+func (r *BtpOperatorReconciler) dryRunApplyOrUpdate(ctx context.Context, u *unstructured.Unstructured) error {
+	preExistingResource := &unstructured.Unstructured{}
+	preExistingResource.SetGroupVersionKind(u.GroupVersionKind())
+	if err := r.Get(ctx, client.ObjectKey{Name: u.GetName(), Namespace: u.GetNamespace()}, preExistingResource); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return r.Patch(ctx, u.DeepCopy(), client.Apply, client.ForceOwnership, client.FieldOwner(operatorName), client.DryRunAll)
+	}
+	obj := u.DeepCopy()
+	obj.SetResourceVersion(preExistingResource.GetResourceVersion())
+	return r.Update(ctx, obj, client.FieldOwner(operatorName), client.DryRunAll)
+}
+
+// servedKinds returns the kinds served by the API server in the group versions of the resources
+func (r *BtpOperatorReconciler) servedKinds(us []*unstructured.Unstructured) (map[schema.GroupVersionKind]bool, error) {
+	if r.discovery == nil {
+		return nil, errors.New("discovery client is not set, so served kinds of module resources can't be checked")
+	}
+	served := make(map[schema.GroupVersionKind]bool)
+	discovered := make(map[schema.GroupVersion]bool)
+	for _, u := range us {
+		groupVersion := u.GroupVersionKind().GroupVersion()
+		if discovered[groupVersion] {
+			continue
+		}
+		discovered[groupVersion] = true
+		resources, err := r.discovery.ServerResourcesForGroupVersion(groupVersion.String())
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("while getting resources of %s: %w", groupVersion, err)
+		}
+		for _, resource := range resources.APIResources {
+			if !strings.Contains(resource.Name, "/") {
+				served[groupVersion.WithKind(resource.Kind)] = true
+			}
+		}
+	}
+	return served, nil
+}
+
+// gvksDefinedByCRDs returns the kinds defined by the CRDs among the resources
+func (r *BtpOperatorReconciler) gvksDefinedByCRDs(us []*unstructured.Unstructured) (map[schema.GroupVersionKind]bool, error) {
+	defined := make(map[schema.GroupVersionKind]bool)
+	for _, u := range us {
+		if u.GetKind() != customResourceDefinitionKind {
+			continue
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+			return nil, fmt.Errorf("while reading CRD %s: %w", u.GetName(), err)
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Served {
+				defined[schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}] = true
+			}
+		}
+	}
+	return defined, nil
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestValidateResources(t *testing.T) {
+	// given
+	newObject := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace(ChartNamespace)
+		return u
+	}
+	crd := newObject("apiextensions.k8s.io/v1", customResourceDefinitionKind, "serviceinstances.services.cloud.sap.com")
+	crd.SetNamespace("")
+	require.NoError(t, unstructured.SetNestedField(crd.Object, map[string]interface{}{
+		"group":    btpOperatorGroup,
+		"names":    map[string]interface{}{"kind": btpOperatorServiceInstance},
+		"versions": []interface{}{map[string]interface{}{"name": "v1", "served": true}},
+	}, "spec"))
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: ChartNamespace}}
+	dryRuns := make([]string, 0)
+	dryRun := func(obj client.Object, opts []string) error {
+		if len(opts) != 1 || opts[0] != metav1.DryRunAll {
+			return fmt.Errorf("%s isn't a dry run", obj.GetName())
+		}
+		dryRuns = append(dryRuns, obj.GetName())
+		if obj.GetName() == "rejected" {
+			return k8serrors.NewInvalid(schema.GroupKind{Kind: secretKind}, "rejected", nil)
+		}
+		return nil
+	}
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patchOpts := &client.PatchOptions{}
+			patchOpts.ApplyOptions(opts)
+			return dryRun(obj, patchOpts.DryRun)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			updateOpts := &client.UpdateOptions{}
+			updateOpts.ApplyOptions(opts)
+			return dryRun(obj, updateOpts.DryRun)
+		},
+	}).Build(), clientgoscheme.Scheme, nil, nil)
+	reconciler.discovery = &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Kind: configMapKind}, {Name: "secrets", Kind: secretKind}}},
+		{GroupVersion: "apiextensions.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "customresourcedefinitions", Kind: customResourceDefinitionKind}}},
+	}}}
+
+	t.Run("should dry run all resources", func(t *testing.T) {
+		// given
+		dryRuns = dryRuns[:0]
+		us := []*unstructured.Unstructured{
+			crd,
+			newObject("services.cloud.sap.com/v1", btpOperatorServiceInstance, "instance"),
+			newObject("v1", configMapKind, "existing"),
+			newObject("v1", secretKind, "new"),
+		}
+
+		// when
+		err := reconciler.validateResources(context.Background(), us)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"serviceinstances.services.cloud.sap.com", "existing", "new"}, dryRuns)
+	})
+
+	t.Run("should report all resources which would be rejected", func(t *testing.T) {
+		// given
+		us := []*unstructured.Unstructured{
+			newObject("apps/v1", deploymentKind, "unserved"),
+			newObject("v1", secretKind, "rejected"),
+			newObject("v1", secretKind, "accepted"),
+		}
+
+		// when
+		err := reconciler.validateResources(context.Background(), us)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 module resource(s) would be rejected: ")
+		assert.Contains(t, err.Error(), "Deployment unserved: apps/v1 is not served")
+		assert.Contains(t, err.Error(), `Secret rejected: Secret "rejected" is invalid`)
+		assert.NotContains(t, err.Error(), "accepted")
+		assert.Equal(t, conditions.ManifestValidationFailed, reasonForError(fmt.Errorf("failed to validate module resources: %w", err), conditions.ReconcileFailed))
+		assert.Equal(t, conditions.ReconcileFailed, reasonForError(errors.New("other"), conditions.ReconcileFailed))
+	})
+
+	t.Run("should report resource of unserved kind with the ManifestValidationFailed reason", func(t *testing.T) {
+		// given
+		dryRuns = dryRuns[:0]
+		us := []*unstructured.Unstructured{newObject("apps/v1", deploymentKind, "unserved")}
+
+		// when
+		err := reconciler.validateResources(context.Background(), us)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "1 module resource(s) would be rejected: Deployment unserved: apps/v1 is not served", err.Error())
+		assert.Empty(t, dryRuns)
+		assert.Equal(t, conditions.ManifestValidationFailed, reasonForError(fmt.Errorf("failed to validate module resources: %w", err), conditions.ReconcileFailed))
+	})
+
+	t.Run("should fail without the discovery client", func(t *testing.T) {
+		// given
+		dryRuns = dryRuns[:0]
+		reconcilerWithoutDiscovery := NewBtpOperatorReconciler(reconciler.Client, clientgoscheme.Scheme, nil, nil)
+
+		// when
+		err := reconcilerWithoutDiscovery.validateResources(context.Background(), []*unstructured.Unstructured{newObject("v1", secretKind, "new")})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "discovery client is not set")
+		assert.Empty(t, dryRuns)
+	})
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+)
+
+const (
+	embeddedChartPath     = "module-chart/chart"
+	embeddedResourcesPath = "module-resources"
+)
+
+// ModuleFS holds the module-chart and module-resources directories embedded into the binary, which are the default source
+// of the chart and module resources. If ChartPath or ResourcesPath is set to another directory, it's read from the disk instead.
+// The legacy default paths, which existing sap-btp-manager ConfigMaps set, point to the embedded directories.
+var ModuleFS fs.FS
+
+// moduleChartFS returns the filesystem and the path of the module chart directory in it
+func moduleChartFS() (fs.FS, string) {
+	return moduleFilesFS(ChartPath, embeddedChartPath)
+}
+
+// moduleResourcesFS returns the filesystem and the path of the module resources directory in it
+func moduleResourcesFS() (fs.FS, string) {
+	return moduleFilesFS(ResourcesPath, embeddedResourcesPath)
+}
+
+// moduleFilesFS returns the embedded directory, or the directory on the disk if another path is set or nothing is embedded,
+// for example in tests
+func moduleFilesFS(dir, embeddedPath string) (fs.FS, string) {
+	if dir != "" && path.Clean(dir) != embeddedPath {
+		return fsutils.OSFS{}, dir
+	}
+	if ModuleFS == nil {
+		return fsutils.OSFS{}, embeddedPath
+	}
+	return ModuleFS, embeddedPath
+}
+
+// describeModulePath returns the path with the information if it's embedded, for logs and messages
+func describeModulePath(fsys fs.FS, path string) string {
+	if _, ok := fsys.(fsutils.OSFS); ok {
+		return path
+	}
+	return fmt.Sprintf("embedded %s", path)
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"github.com/kyma-project/btp-manager/internal/manifest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestModuleFilesFS(t *testing.T) {
+	defaultModuleFS, defaultChartPath, defaultResourcesPath := ModuleFS, ChartPath, ResourcesPath
+	defer func() { ModuleFS, ChartPath, ResourcesPath = defaultModuleFS, defaultChartPath, defaultResourcesPath }()
+	// the repository root has the same layout as the embedded directories
+	embedded := os.DirFS("..")
+
+	t.Run("should use the embedded directories by default", func(t *testing.T) {
+		// given
+		ModuleFS, ChartPath, ResourcesPath = embedded, "", ""
+		reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+		// when
+		chartFS, chartPath := moduleChartFS()
+		resources, err := reconciler.createUnstructuredObjectsFromManifestsDir(reconciler.getResourcesToApplyPath())
+
+		// then
+		assert.Equal(t, embedded, chartFS)
+		assert.Equal(t, embeddedChartPath, chartPath)
+		assert.Equal(t, "embedded module-chart/chart", describeModulePath(chartFS, chartPath))
+		require.NoError(t, err)
+		assert.NotEmpty(t, resources)
+	})
+
+	t.Run("should use the directories on the disk if the paths are set", func(t *testing.T) {
+		// given
+		ModuleFS, ChartPath, ResourcesPath = embedded, "../module-chart/chart", "../module-resources"
+
+		// when
+		chartFS, chartPath := moduleChartFS()
+		resourcesFS, resourcesPath := moduleResourcesFS()
+
+		// then
+		assert.Equal(t, fsutils.OSFS{}, chartFS)
+		assert.Equal(t, "../module-chart/chart", chartPath)
+		assert.Equal(t, "../module-chart/chart", describeModulePath(chartFS, chartPath))
+		assert.Equal(t, fsutils.OSFS{}, resourcesFS)
+		assert.Equal(t, "../module-resources", resourcesPath)
+	})
+
+	t.Run("should use the embedded directories for the legacy default paths", func(t *testing.T) {
+		// given
+		ModuleFS, ChartPath, ResourcesPath = embedded, "./module-chart/chart", "./module-resources"
+
+		// when
+		chartFS, chartPath := moduleChartFS()
+		resourcesFS, resourcesPath := moduleResourcesFS()
+
+		// then
+		assert.Equal(t, embedded, chartFS)
+		assert.Equal(t, embeddedChartPath, chartPath)
+		assert.Equal(t, embedded, resourcesFS)
+		assert.Equal(t, embeddedResourcesPath, resourcesPath)
+	})
+
+	t.Run("should use the directories on the disk if nothing is embedded", func(t *testing.T) {
+		// given
+		ModuleFS, ChartPath, ResourcesPath = nil, "", ""
+
+		// when
+		resourcesFS, resourcesPath := moduleResourcesFS()
+
+		// then
+		assert.Equal(t, fsutils.OSFS{}, resourcesFS)
+		assert.Equal(t, embeddedResourcesPath, resourcesPath)
+	})
+}
+
+func TestCreateUnstructuredObjectsFromManifestsDirVerification(t *testing.T) {
+	// given
+	defaultModuleFS, defaultResourcesPath, defaultPublicKey := ModuleFS, ResourcesPath, ManifestsPublicKey
+	defer func() {
+		ModuleFS, ResourcesPath, ManifestsPublicKey = defaultModuleFS, defaultResourcesPath, defaultPublicKey
+	}()
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	ModuleFS = fstest.MapFS{
+		"module-resources/apply/secret.yml": {Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n")},
+	}
+	ResourcesPath, ManifestsPublicKey = "", base64.StdEncoding.EncodeToString(publicKey)
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+
+	// when
+	_, err = reconciler.createUnstructuredObjectsFromManifestsDir(reconciler.getResourcesToApplyPath())
+
+	// then
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksums.json file is missing")
+	assert.Equal(t, conditions.ManifestVerificationFailed, reasonForError(fmt.Errorf("failed to create applicable objects from manifests: %w", err), conditions.ProvisioningFailed))
+	assert.Equal(t, conditions.ProvisioningFailed, reasonForError(errors.New("other"), conditions.ProvisioningFailed))
+}
+
+func TestCreateUnstructuredObjectsFromManifestsDirCommittedChecksums(t *testing.T) {
+	// given
+	defaultModuleFS, defaultResourcesPath, defaultPublicKey := ModuleFS, ResourcesPath, ManifestsPublicKey
+	defer func() {
+		ModuleFS, ResourcesPath, ManifestsPublicKey = defaultModuleFS, defaultResourcesPath, defaultPublicKey
+	}()
+	// the repository root has the same layout as the embedded directories
+	ModuleFS, ResourcesPath, ManifestsPublicKey = os.DirFS(".."), "", ""
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+	resourcesFS, _ := moduleResourcesFS()
+
+	for _, dir := range []string{reconciler.getResourcesToApplyPath(), reconciler.getResourcesToDeletePath()} {
+		_, err := fs.Stat(resourcesFS, path.Join(dir, manifest.ChecksumsFile))
+		require.NoError(t, err, "run make module-resources-checksums")
+
+		// when
+		_, err = reconciler.createUnstructuredObjectsFromManifestsDir(dir)
+
+		// then
+		assert.NoError(t, err, "run make module-resources-checksums")
+	}
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// resourcePatch is an entry of a patches ConfigMap, similar to kustomize patches. A patch which is a list of operations
+// is a JSON6902 patch and requires the target, other patches are strategic merge patches, which target the object
+// with their own kind and name unless the target is given.
+type resourcePatch struct {
+	Target *patchTarget `json:"target,omitempty"`
+	Patch  string       `json:"patch"`
+
+	source string
+}
+
+type patchTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (t *patchTarget) String() string {
+	return fmt.Sprintf("%s/%s", t.Kind, t.Name)
+}
+
+func (t *patchTarget) matches(u *unstructured.Unstructured) bool {
+	gvk := u.GroupVersionKind()
+	return t.Kind == gvk.Kind && t.Name == u.GetName() &&
+		(t.Group == "" || t.Group == gvk.Group) &&
+		(t.Version == "" || t.Version == gvk.Version) &&
+		(t.Namespace == "" || t.Namespace == u.GetNamespace())
+}
+
+// patchesResult is the outcome of applying patches to module resources, failed patches don't block the reconciliation
+type patchesResult struct {
+	total    int
+	failures []string
+}
+
+// getResourcePatches reads patches from ConfigMaps with the ModuleResourcesPatchLabelKey label, sorted by the ConfigMap name and the data key.
+// Every data key holds a YAML list of patches. The ConfigMaps are read as unstructured, because the cache holds only ConfigMaps with the managed-by label.
+func (r *BtpOperatorReconciler) getResourcePatches(ctx context.Context) ([]resourcePatch, []string, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(configMapKind + "List"))
+	if err := r.List(ctx, list, client.InNamespace(ChartNamespace), client.MatchingLabels{v1alpha1.ModuleResourcesPatchLabelKey: "true"}); err != nil {
+		return nil, nil, fmt.Errorf("while listing patches ConfigMaps: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+
+	patches := make([]resourcePatch, 0)
+	failures := make([]string, 0)
+	for _, cm := range list.Items {
+		data, _, _ := unstructured.NestedStringMap(cm.Object, "data")
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			entries := make([]resourcePatch, 0)
+			if err := yaml.Unmarshal([]byte(data[key]), &entries); err != nil {
+				failures = append(failures, fmt.Sprintf("%s/%s: %s", cm.GetName(), key, err))
+				continue
+			}
+			for i := range entries {
+				entries[i].source = fmt.Sprintf("%s/%s[%d]", cm.GetName(), key, i)
+				patches = append(patches, entries[i])
+			}
+		}
+	}
+	return patches, failures, nil
+}
+
+// applyResourcePatches applies patches to the matching module resources. A resource is changed only if the patch applies cleanly,
+// and failures are returned to be reported in the ModuleResourcesPatched condition.
+func (r *BtpOperatorReconciler) applyResourcePatches(ctx context.Context, us []*unstructured.Unstructured) *patchesResult {
+	patches, failures, err := r.getResourcePatches(ctx)
+	if err != nil {
+		return &patchesResult{total: 1, failures: []string{err.Error()}}
+	}
+
+	result := &patchesResult{total: len(patches) + len(failures), failures: failures}
+	for _, patch := range patches {
+		if err := r.applyResourcePatch(patch, us); err != nil {
+			result.failures = append(result.failures, fmt.Sprintf("%s: %s", patch.source, err))
+		}
+	}
+	return result
+}
+
+func (r *BtpOperatorReconciler) applyResourcePatch(patch resourcePatch, us []*unstructured.Unstructured) error {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	var content interface{}
+	if err := json.Unmarshal(patchJSON, &content); err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+
+	var operations jsonpatch.Patch
+	target := patch.Target
+	switch c := content.(type) {
+	case []interface{}:
+		if target == nil {
+			return fmt.Errorf("target is required for JSON6902 patches")
+		}
+		if operations, err = jsonpatch.DecodePatch(patchJSON); err != nil {
+			return fmt.Errorf("invalid JSON6902 patch: %w", err)
+		}
+	case map[string]interface{}:
+		if target == nil {
+			u := &unstructured.Unstructured{Object: c}
+			gvk := u.GroupVersionKind()
+			target = &patchTarget{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, Name: u.GetName(), Namespace: u.GetNamespace()}
+		}
+	default:
+		return fmt.Errorf("patch must be a list of JSON6902 operations or a strategic merge patch")
+	}
+	if target.Kind == "" || target.Name == "" {
+		return fmt.Errorf("target kind and name are required")
+	}
+
+	matched := false
+	for _, u := range us {
+		if !target.matches(u) {
+			continue
+		}
+		matched = true
+		original, err := json.Marshal(u.Object)
+		if err != nil {
+			return err
+		}
+		var patched []byte
+		if operations != nil {
+			patched, err = operations.Apply(original)
+		} else {
+			patched, err = r.strategicMergePatch(u.GroupVersionKind(), original, patchJSON)
+		}
+		if err != nil {
+			return fmt.Errorf("while patching %s: %w", target, err)
+		}
+
+		result := &unstructured.Unstructured{}
+		if err := result.UnmarshalJSON(patched); err != nil {
+			return fmt.Errorf("while reading patched %s: %w", target, err)
+		}
+		if result.GroupVersionKind() != u.GroupVersionKind() || result.GetName() != u.GetName() || result.GetNamespace() != u.GetNamespace() {
+			return fmt.Errorf("patch must not change the kind, name, or namespace of %s", target)
+		}
+		u.Object = result.Object
+	}
+	if !matched {
+		return fmt.Errorf("no module resource matches %s", target)
+	}
+	return nil
+}
+
+// strategicMergePatch uses the patch strategy of types known to the scheme, and the JSON merge patch for other types, for example CRDs
+func (r *BtpOperatorReconciler) strategicMergePatch(gvk schema.GroupVersionKind, original, patch []byte) ([]byte, error) {
+	if typed, err := r.Scheme.New(gvk); err == nil {
+		return strategicpatch.StrategicMergePatch(original, patch, typed)
+	}
+	return jsonpatch.MergePatch(original, patch)
+}
+
+// patchesCondition returns the ModuleResourcesPatched condition, or nil if there are no patches
+func (r *BtpOperatorReconciler) patchesCondition(result *patchesResult) *metav1.Condition {
+	if result == nil || result.total == 0 {
+		return nil
+	}
+	if len(result.failures) > 0 {
+		failures := result.failures
+		if len(failures) > maxReportedResources {
+			failures = append(failures[:maxReportedResources:maxReportedResources], fmt.Sprintf("and %d more", len(result.failures)-maxReportedResources))
+		}
+		return &metav1.Condition{
+			Type:    conditions.ModuleResourcesPatchedType,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(conditions.PatchFailed),
+			Message: fmt.Sprintf("%d of %d patch(es) failed: %s", len(result.failures), result.total, strings.Join(failures, "; ")),
+		}
+	}
+	return &metav1.Condition{
+		Type:    conditions.ModuleResourcesPatchedType,
+		Status:  metav1.ConditionTrue,
+		Reason:  string(conditions.PatchesApplied),
+		Message: fmt.Sprintf("%d patch(es) applied", result.total),
+	}
+}
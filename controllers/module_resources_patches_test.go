@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyResourcePatches(t *testing.T) {
+	newDeployment := func() *unstructured.Unstructured {
+		deployment := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "sap-btp-operator-controller-manager", Namespace: ChartNamespace},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "manager", Image: "manager:v1"},
+							{Name: "kube-rbac-proxy", Image: "proxy:v1"},
+						},
+					},
+				},
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: obj}
+	}
+	newPatchesConfigMap := func(name string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ChartNamespace,
+				Labels:    map[string]string{v1alpha1.ModuleResourcesPatchLabelKey: "true"},
+			},
+			Data: data,
+		}
+	}
+	newReconciler := func(objs ...*corev1.ConfigMap) *BtpOperatorReconciler {
+		builder := fake.NewClientBuilder()
+		for _, obj := range objs {
+			builder.WithObjects(obj)
+		}
+		return NewBtpOperatorReconciler(builder.Build(), clientgoscheme.Scheme, nil, nil)
+	}
+	containers := func(u *unstructured.Unstructured) []interface{} {
+		containers, _, err := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		return containers
+	}
+
+	t.Run("should apply strategic merge and JSON6902 patches in order", func(t *testing.T) {
+		// given
+		reconciler := newReconciler(
+			newPatchesConfigMap("b-patches", map[string]string{"patches.yaml": `
+- target:
+    kind: Deployment
+    name: sap-btp-operator-controller-manager
+  patch: |
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`}),
+			newPatchesConfigMap("a-patches", map[string]string{"patches.yaml": `
+- patch: |
+    apiVersion: apps/v1
+    kind: Deployment
+    metadata:
+      name: sap-btp-operator-controller-manager
+    spec:
+      replicas: 3
+      template:
+        spec:
+          containers:
+          - name: manager
+            image: manager:v2
+`}),
+		)
+		deployment := newDeployment()
+
+		// when
+		result := reconciler.applyResourcePatches(context.Background(), []*unstructured.Unstructured{deployment})
+
+		// then
+		assert.Equal(t, 2, result.total)
+		assert.Empty(t, result.failures)
+		replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+		assert.Equal(t, int64(2), replicas)
+		require.Len(t, containers(deployment), 2)
+		assert.Equal(t, "manager:v2", containers(deployment)[0].(map[string]interface{})["image"])
+		assert.Equal(t, "proxy:v1", containers(deployment)[1].(map[string]interface{})["image"])
+
+		condition := reconciler.patchesCondition(result)
+		require.NotNil(t, condition)
+		assert.Equal(t, conditions.ModuleResourcesPatchedType, condition.Type)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, string(conditions.PatchesApplied), condition.Reason)
+	})
+
+	t.Run("should report failed patches and keep the resources unchanged", func(t *testing.T) {
+		// given
+		reconciler := newReconciler(newPatchesConfigMap("patches", map[string]string{
+			"invalid.yaml": "not a list",
+			"patches.yaml": `
+- patch: |
+    - op: remove
+      path: /spec/replicas
+- target:
+    kind: Deployment
+    name: missing
+  patch: |
+    - op: remove
+      path: /spec/replicas
+- target:
+    kind: Deployment
+    name: sap-btp-operator-controller-manager
+  patch: |
+    - op: replace
+      path: /metadata/name
+      value: renamed
+- target:
+    kind: Deployment
+    name: sap-btp-operator-controller-manager
+  patch: |
+    - op: test
+      path: /spec/replicas
+      value: 5
+`,
+		}))
+		deployment := newDeployment()
+		original := deployment.DeepCopy()
+
+		// when
+		result := reconciler.applyResourcePatches(context.Background(), []*unstructured.Unstructured{deployment})
+
+		// then
+		assert.Equal(t, 5, result.total)
+		require.Len(t, result.failures, 5)
+		assert.Contains(t, result.failures[0], "patches/invalid.yaml")
+		assert.Contains(t, result.failures[1], "target is required")
+		assert.Contains(t, result.failures[2], "no module resource matches Deployment/missing")
+		assert.Contains(t, result.failures[3], "must not change the kind, name, or namespace")
+		assert.Contains(t, result.failures[4], "patches/patches.yaml[3]")
+		assert.Equal(t, original, deployment)
+
+		condition := reconciler.patchesCondition(result)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, string(conditions.PatchFailed), condition.Reason)
+		assert.Contains(t, condition.Message, "5 of 5 patch(es) failed")
+	})
+
+	t.Run("should ignore ConfigMaps without the label", func(t *testing.T) {
+		// given
+		cm := newPatchesConfigMap("patches", map[string]string{"patches.yaml": "- patch: invalid"})
+		cm.Labels = nil
+		reconciler := newReconciler(cm)
+
+		// when
+		result := reconciler.applyResourcePatches(context.Background(), []*unstructured.Unstructured{newDeployment()})
+
+		// then
+		assert.Zero(t, result.total)
+		assert.Nil(t, reconciler.patchesCondition(result))
+	})
+}
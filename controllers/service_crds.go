@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const serviceCRDsGroup = "services.cloud.sap.com"
+
+// serviceCRDsWatcher tracks the ServiceInstance and ServiceBinding CRDs with an event handler on the CustomResourceDefinition informer,
+// so that checking the CRDs doesn't require requests to the API server. Every change of the tracked CRDs is sent to the events channel,
+// and onChange is called when both CRDs become established, one of them stops being established, or their served or storage versions change.
+type serviceCRDsWatcher struct {
+	mu          sync.RWMutex
+	established map[string]bool
+	versions    map[string]string
+	hasSynced   func() bool
+	onChange    func(available bool)
+	events      chan event.GenericEvent
+}
+
+func newServiceCRDsWatcher(onChange func(available bool)) *serviceCRDsWatcher {
+	return &serviceCRDsWatcher{
+		established: make(map[string]bool),
+		versions:    make(map[string]string),
+		onChange:    onChange,
+		events:      make(chan event.GenericEvent, 1),
+	}
+}
+
+func crdName(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("%ss.%s", strings.ToLower(gvk.Kind), gvk.Group)
+}
+
+func isCRDEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	if !crd.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// crdVersions returns the served versions of the CRD with its storage version, which the service API versions are resolved from
+func crdVersions(crd *apiextensionsv1.CustomResourceDefinition) string {
+	versions := make([]string, 0, len(crd.Spec.Versions))
+	storage := ""
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions = append(versions, version.Name)
+		}
+		if version.Storage {
+			storage = version.Name
+		}
+	}
+	sort.Strings(versions)
+	return fmt.Sprintf("%s;storage=%s", strings.Join(versions, ","), storage)
+}
+
+// register adds the event handler to the CustomResourceDefinition informer without waiting for the cache to start
+func (w *serviceCRDsWatcher) register(ctx context.Context, c cache.Cache) error {
+	informer, err := c.GetInformer(ctx, &apiextensionsv1.CustomResourceDefinition{}, cache.BlockUntilSynced(false))
+	if err != nil {
+		return fmt.Errorf("while getting CustomResourceDefinition informer: %w", err)
+	}
+	registration, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    w.update,
+		UpdateFunc: func(_, obj any) { w.update(obj) },
+		DeleteFunc: w.remove,
+	})
+	if err != nil {
+		return fmt.Errorf("while adding CustomResourceDefinition event handler: %w", err)
+	}
+	w.hasSynced = registration.HasSynced
+	return nil
+}
+
+func (w *serviceCRDsWatcher) update(obj any) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok || crd.Spec.Group != serviceCRDsGroup {
+		return
+	}
+	w.set(crd, true, isCRDEstablished(crd))
+}
+
+func (w *serviceCRDsWatcher) remove(obj any) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok || crd.Spec.Group != serviceCRDsGroup {
+		return
+	}
+	w.set(crd, false, false)
+}
+
+func (w *serviceCRDsWatcher) set(crd *apiextensionsv1.CustomResourceDefinition, exists, established bool) {
+	versions := ""
+	if exists {
+		versions = crdVersions(crd)
+	}
+
+	w.mu.Lock()
+	availableBefore := w.availableLocked()
+	establishedBefore, existedBefore := w.established[crd.GetName()]
+	versionsBefore := w.versions[crd.GetName()]
+	if exists {
+		w.established[crd.GetName()] = established
+		w.versions[crd.GetName()] = versions
+	} else {
+		delete(w.established, crd.GetName())
+		delete(w.versions, crd.GetName())
+	}
+	availableAfter := w.availableLocked()
+	w.mu.Unlock()
+
+	if (availableBefore != availableAfter || (availableAfter && versionsBefore != versions)) && w.onChange != nil {
+		w.onChange(availableAfter)
+	}
+	if existedBefore != exists || establishedBefore != established || versionsBefore != versions {
+		// a pending event already triggers the reconciliation which reads the current state
+		select {
+		case w.events <- event.GenericEvent{Object: crd}:
+		default:
+		}
+	}
+}
+
+func (w *serviceCRDsWatcher) availableLocked() bool {
+	return w.established[crdName(instanceGvk)] && w.established[crdName(bindingGvk)]
+}
+
+func (w *serviceCRDsWatcher) synced() bool {
+	return w.hasSynced != nil && w.hasSynced()
+}
+
+// exists returns whether the CRD of the given GVK exists, the second value is false until the informer has synced
+func (w *serviceCRDsWatcher) exists(gvk schema.GroupVersionKind) (bool, bool) {
+	if !w.synced() {
+		return false, false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, exists := w.established[crdName(gvk)]
+	return exists, true
+}
+
+// notEstablished returns sorted names of the ServiceInstance and ServiceBinding CRDs which are missing or not established
+func (w *serviceCRDsWatcher) notEstablished() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	names := make([]string, 0)
+	for _, gvk := range []schema.GroupVersionKind{instanceGvk, bindingGvk} {
+		if !w.established[crdName(gvk)] {
+			names = append(names, crdName(gvk))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// condition returns the ServiceCRDsAvailable condition, or nil until the informer has synced
+func (w *serviceCRDsWatcher) condition() *metav1.Condition {
+	if !w.synced() {
+		return nil
+	}
+	if missing := w.notEstablished(); len(missing) > 0 {
+		return &metav1.Condition{
+			Type:    conditions.ServiceCRDsAvailableType,
+			Status:  metav1.ConditionFalse,
+			Reason:  string(conditions.ServiceCRDsNotEstablished),
+			Message: fmt.Sprintf("CRDs are not established: %s", strings.Join(missing, ", ")),
+		}
+	}
+	return &metav1.Condition{
+		Type:    conditions.ServiceCRDsAvailableType,
+		Status:  metav1.ConditionTrue,
+		Reason:  string(conditions.ServiceCRDsEstablished),
+		Message: "ServiceInstance and ServiceBinding CRDs are established",
+	}
+}
+
+// onServiceCRDsChange resolves the service API versions from the CRDs again and runs the ServiceInstance controller only while the CRDs it watches are established
+func (r *BtpOperatorReconciler) onServiceCRDsChange(available bool) {
+	r.serviceVersions.invalidate()
+	instanceGvk, bindingGvk := r.serviceInstanceGvk(), r.serviceBindingGvk()
+	log.Log.Info("ServiceInstance and ServiceBinding CRDs changed", "available", available, "instanceVersion", instanceGvk.Version, "bindingVersion", bindingGvk.Version)
+	if r.instanceBindingService != nil {
+		r.instanceBindingService.SetServiceCRDs(available, instanceGvk, bindingGvk)
+	}
+}
+
+// updateServiceCRDsCondition publishes the availability of the ServiceInstance and ServiceBinding CRDs in the CR status.
+// The condition is added after the Ready condition, and errors are only logged because the condition is informational.
+func (r *BtpOperatorReconciler) updateServiceCRDsCondition(ctx context.Context, cr *v1alpha1.BtpOperator) {
+	logger := log.FromContext(ctx)
+	if r.serviceCRDs == nil || cr.Status.State == "" {
+		return
+	}
+	condition := r.serviceCRDs.condition()
+	if condition == nil {
+		return
+	}
+	if hasCondition(cr.Status.Conditions, condition) {
+		return
+	}
+
+	patch := client.MergeFromWithOptions(cr.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	conditions.SetStatusCondition(&cr.Status.Conditions, *condition)
+	if err := r.Status().Patch(ctx, cr, patch); err != nil {
+		logger.Error(err, "while updating the ServiceCRDsAvailable condition")
+	}
+}
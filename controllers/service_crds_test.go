@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestServiceCRDsWatcher(t *testing.T) {
+	newCRD := func(gvk schema.GroupVersionKind, established bool) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		crd.SetName(crdName(gvk))
+		crd.Spec.Group = gvk.Group
+		status := apiextensionsv1.ConditionFalse
+		if established {
+			status = apiextensionsv1.ConditionTrue
+		}
+		crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: status}}
+		return crd
+	}
+	newSyncedWatcher := func(changes *[]bool) *serviceCRDsWatcher {
+		watcher := newServiceCRDsWatcher(func(available bool) { *changes = append(*changes, available) })
+		watcher.hasSynced = func() bool { return true }
+		return watcher
+	}
+
+	t.Run("should not answer until synced", func(t *testing.T) {
+		// given
+		watcher := newServiceCRDsWatcher(nil)
+		watcher.update(newCRD(instanceGvk, true))
+
+		// when
+		_, synced := watcher.exists(instanceGvk)
+
+		// then
+		assert.False(t, synced)
+		assert.Nil(t, watcher.condition())
+	})
+
+	t.Run("should report availability when both CRDs are established", func(t *testing.T) {
+		// given
+		changes := make([]bool, 0)
+		watcher := newSyncedWatcher(&changes)
+
+		// when
+		watcher.update(newCRD(instanceGvk, true))
+		watcher.update(newCRD(bindingGvk, false))
+
+		// then
+		exists, synced := watcher.exists(bindingGvk)
+		assert.True(t, synced)
+		assert.True(t, exists)
+		assert.Empty(t, changes)
+		condition := watcher.condition()
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, string(conditions.ServiceCRDsNotEstablished), condition.Reason)
+		assert.Equal(t, "CRDs are not established: servicebindings.services.cloud.sap.com", condition.Message)
+
+		// when
+		watcher.update(newCRD(bindingGvk, true))
+
+		// then
+		assert.Equal(t, []bool{true}, changes)
+		condition = watcher.condition()
+		require.NotNil(t, condition)
+		assert.Equal(t, conditions.ServiceCRDsAvailableType, condition.Type)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	})
+
+	t.Run("should report unavailability when a CRD is removed", func(t *testing.T) {
+		// given
+		changes := make([]bool, 0)
+		watcher := newSyncedWatcher(&changes)
+		watcher.update(newCRD(instanceGvk, true))
+		watcher.update(newCRD(bindingGvk, true))
+
+		// when
+		watcher.remove(toolscache.DeletedFinalStateUnknown{Obj: newCRD(instanceGvk, true)})
+
+		// then
+		assert.Equal(t, []bool{true, false}, changes)
+		exists, _ := watcher.exists(instanceGvk)
+		assert.False(t, exists)
+	})
+
+	t.Run("should report a change of served versions", func(t *testing.T) {
+		// given
+		changes := make([]bool, 0)
+		watcher := newSyncedWatcher(&changes)
+		watcher.update(newCRD(instanceGvk, true))
+		watcher.update(newCRD(bindingGvk, true))
+		<-watcher.events
+		crd := newCRD(instanceGvk, true)
+		crd.Spec.Versions = []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true}, {Name: "v2", Served: true}}
+
+		// when
+		watcher.update(crd)
+
+		// then
+		assert.Equal(t, []bool{true, true}, changes)
+		assert.Len(t, watcher.events, 1)
+	})
+
+	t.Run("should report a change of the storage version", func(t *testing.T) {
+		// given
+		changes := make([]bool, 0)
+		watcher := newSyncedWatcher(&changes)
+		crd := newCRD(instanceGvk, true)
+		crd.Spec.Versions = []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: true, Storage: true}, {Name: "v2", Served: true}}
+		watcher.update(crd)
+		watcher.update(newCRD(bindingGvk, true))
+		<-watcher.events
+		crd = crd.DeepCopy()
+		crd.Spec.Versions[0].Storage, crd.Spec.Versions[1].Storage = false, true
+
+		// when
+		watcher.update(crd)
+
+		// then
+		assert.Equal(t, []bool{true, true}, changes)
+		assert.Len(t, watcher.events, 1)
+	})
+
+	t.Run("should treat a CRD in deletion as not established", func(t *testing.T) {
+		// given
+		crd := newCRD(instanceGvk, true)
+		deletionTimestamp := metav1.NewTime(time.Now())
+		crd.SetDeletionTimestamp(&deletionTimestamp)
+
+		// then
+		assert.False(t, isCRDEstablished(crd))
+	})
+
+	t.Run("should ignore CRDs of other groups", func(t *testing.T) {
+		// given
+		changes := make([]bool, 0)
+		watcher := newSyncedWatcher(&changes)
+
+		// when
+		watcher.update(newCRD(schema.GroupVersionKind{Group: "example.com", Kind: "Other"}, true))
+
+		// then
+		assert.Empty(t, watcher.established)
+		assert.Empty(t, watcher.events)
+	})
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Backup Secrets are not labeled with the managed-by label, so they are neither cached nor removed with module resources
+const (
+	backupLabelKey        = "operator.kyma-project.io/btp-manager-backup"
+	backupSourceLabelKey  = "operator.kyma-project.io/backup-source-uid"
+	backupChunkAnnotation = "operator.kyma-project.io/backup-chunk"
+	backupNamePrefix      = "btp-manager-backup"
+	backupDataKey         = "resources.json"
+	maxBackupChunkSize    = 512 * 1024
+)
+
+var backupLabelFilter = client.MatchingLabels{backupLabelKey: "true"}
+
+// backupServiceResources exports ServiceInstances and ServiceBindings into Secrets in the ChartNamespace before their finalizers are removed.
+// Entries from an earlier, interrupted soft delete of the same BtpOperator CR are kept, so that the backup covers resources removed in the previous attempt.
+// Entries of the resources to orphan are marked with the force-orphaned annotation.
+func (r *BtpOperatorReconciler) backupServiceResources(ctx context.Context, cr *v1alpha1.BtpOperator, toOrphan []unstructured.Unstructured) error {
+	logger := log.FromContext(ctx)
+
+	existingSecrets, err := r.getBackupSecrets(ctx, client.MatchingLabels{backupSourceLabelKey: string(cr.GetUID())})
+	if err != nil {
+		return err
+	}
+	entries, err := r.readBackupEntries(existingSecrets)
+	if err != nil {
+		return err
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{r.serviceInstanceGvk(), r.serviceBindingGvk()} {
+		items, err := r.listResourcesInAllNamespaces(ctx, gvk)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			entry := r.toBackupEntry(item)
+			entries[r.backupEntryKey(entry)] = entry
+		}
+	}
+	orphanedAt := time.Now().UTC().Format(time.RFC3339)
+	for _, item := range toOrphan {
+		entry, exists := entries[r.backupEntryKey(r.toBackupEntry(item))]
+		if !exists {
+			continue
+		}
+		u := unstructured.Unstructured{Object: entry}
+		annotations := u.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[forceOrphanedAnnotation] = orphanedAt
+		u.SetAnnotations(annotations)
+	}
+	if len(entries) == 0 {
+		logger.Info("no Service Instances and Service Bindings to back up")
+		return nil
+	}
+
+	chunks, err := r.chunkBackupEntries(entries)
+	if err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		if err := r.applyBackupSecret(ctx, cr, i, chunk); err != nil {
+			return err
+		}
+	}
+	for _, secret := range existingSecrets {
+		index, err := strconv.Atoi(secret.GetAnnotations()[backupChunkAnnotation])
+		if err == nil && index < len(chunks) {
+			continue
+		}
+		if err := r.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("while deleting outdated backup Secret %s: %w", secret.GetName(), err)
+		}
+	}
+
+	logger.Info(fmt.Sprintf("backed up %d Service Instances and Service Bindings in %d Secret(s)", len(entries), len(chunks)))
+	return nil
+}
+
+// getBackupSecrets reads backup Secrets as unstructured to bypass the cache
+func (r *BtpOperatorReconciler) getBackupSecrets(ctx context.Context, opts ...client.ListOption) ([]corev1.Secret, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(secretKind + "List"))
+	opts = append(opts, client.InNamespace(ChartNamespace), backupLabelFilter)
+	if err := r.List(ctx, list, opts...); err != nil {
+		return nil, fmt.Errorf("while listing backup Secrets: %w", err)
+	}
+
+	secrets := make([]corev1.Secret, 0, len(list.Items))
+	for _, item := range list.Items {
+		secret := corev1.Secret{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &secret); err != nil {
+			return nil, fmt.Errorf("while converting backup Secret %s: %w", item.GetName(), err)
+		}
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].CreationTimestamp.Before(&secrets[j].CreationTimestamp)
+	})
+	return secrets, nil
+}
+
+// readBackupEntries returns entries from all given Secrets, entries from later Secrets override earlier ones
+func (r *BtpOperatorReconciler) readBackupEntries(secrets []corev1.Secret) (map[string]map[string]interface{}, error) {
+	entries := make(map[string]map[string]interface{})
+	for _, secret := range secrets {
+		var chunk []map[string]interface{}
+		if err := json.Unmarshal(secret.Data[backupDataKey], &chunk); err != nil {
+			return nil, fmt.Errorf("while reading backup Secret %s: %w", secret.GetName(), err)
+		}
+		for _, entry := range chunk {
+			entries[r.backupEntryKey(entry)] = entry
+		}
+	}
+	return entries, nil
+}
+
+// toBackupEntry keeps data required to recreate the resource and the ID of the corresponding resource in SAP BTP
+// serviceIDField returns the status field with the ID in SAP BTP, which sap-btp-service-operator identifies the resource with
+func serviceIDField(kind string) string {
+	if kind == btpOperatorServiceBinding {
+		return "bindingID"
+	}
+	return "instanceID"
+}
+
+func (r *BtpOperatorReconciler) toBackupEntry(item unstructured.Unstructured) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":      item.GetName(),
+		"namespace": item.GetNamespace(),
+	}
+	if labels := item.GetLabels(); len(labels) > 0 {
+		_ = unstructured.SetNestedStringMap(metadata, labels, "labels")
+	}
+	if annotations := item.GetAnnotations(); len(annotations) > 0 {
+		_ = unstructured.SetNestedStringMap(metadata, annotations, "annotations")
+	}
+	entry := map[string]interface{}{
+		"apiVersion": item.GetAPIVersion(),
+		"kind":       item.GetKind(),
+		"metadata":   metadata,
+	}
+	if spec, found, _ := unstructured.NestedMap(item.Object, "spec"); found {
+		entry["spec"] = spec
+	}
+
+	idField := serviceIDField(item.GetKind())
+	if id, found, _ := unstructured.NestedString(item.Object, "status", idField); found && id != "" {
+		entry["status"] = map[string]interface{}{idField: id}
+	}
+
+	return entry
+}
+
+func (r *BtpOperatorReconciler) backupEntryKey(entry map[string]interface{}) string {
+	u := unstructured.Unstructured{Object: entry}
+	return fmt.Sprintf("%s/%s/%s", u.GetKind(), u.GetNamespace(), u.GetName())
+}
+
+func (r *BtpOperatorReconciler) chunkBackupEntries(entries map[string]map[string]interface{}) ([][]byte, error) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	chunks := make([][]byte, 0)
+	current := make([]json.RawMessage, 0)
+	currentSize := 0
+	flush := func() error {
+		data, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, data)
+		current = make([]json.RawMessage, 0)
+		currentSize = 0
+		return nil
+	}
+	for _, key := range keys {
+		data, err := json.Marshal(entries[key])
+		if err != nil {
+			return nil, fmt.Errorf("while serializing %s: %w", key, err)
+		}
+		if len(current) > 0 && currentSize+len(data) > maxBackupChunkSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		current = append(current, data)
+		currentSize += len(data) + 1
+	}
+	if len(current) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return chunks, nil
+}
+
+func (r *BtpOperatorReconciler) applyBackupSecret(ctx context.Context, cr *v1alpha1.BtpOperator, index int, data []byte) error {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       secretKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-%d", backupNamePrefix, cr.GetUID(), index),
+			Namespace: ChartNamespace,
+			Labels: map[string]string{
+				backupLabelKey:       "true",
+				backupSourceLabelKey: string(cr.GetUID()),
+			},
+			Annotations: map[string]string{
+				backupChunkAnnotation: strconv.Itoa(index),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{backupDataKey: data},
+	}
+
+	if err := r.Patch(ctx, secret, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName)); err != nil {
+		return fmt.Errorf("while applying backup Secret %s: %w", secret.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func (r *BtpOperatorReconciler) isRestoreRequested(cr *v1alpha1.BtpOperator) bool {
+	return cr.GetAnnotations()[v1alpha1.RestoreBackupAnnotation] == "true"
+}
+
+// restoreServiceResources recreates missing ServiceInstances and ServiceBindings from backup Secrets with their IDs, so that
+// sap-btp-service-operator re-adopts the corresponding resources in SAP BTP instead of provisioning new ones. Instances are restored before bindings.
+// Backup Secrets are deleted once all resources from the backup exist in the cluster.
+func (r *BtpOperatorReconciler) restoreServiceResources(ctx context.Context, cr *v1alpha1.BtpOperator) error {
+	logger := log.FromContext(ctx)
+
+	secrets, err := r.getBackupSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+	entries, err := r.readBackupEntries(secrets)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("restoring %d Service Instances and Service Bindings from %d backup Secret(s)", len(entries), len(secrets)))
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	restoreStatus := &v1alpha1.RestoreStatus{Total: len(entries)}
+	for _, kind := range []string{btpOperatorServiceInstance, btpOperatorServiceBinding} {
+		for _, key := range keys {
+			u := &unstructured.Unstructured{Object: entries[key]}
+			if u.GetKind() != kind {
+				continue
+			}
+			if err := r.restoreServiceResource(ctx, u); err != nil {
+				logger.Error(err, "while restoring resource", "kind", u.GetKind(), "name", u.GetName(), "namespace", u.GetNamespace())
+				restoreStatus.Failed = append(restoreStatus.Failed, v1alpha1.RestoreFailure{
+					Kind:      u.GetKind(),
+					Namespace: u.GetNamespace(),
+					Name:      u.GetName(),
+					Message:   err.Error(),
+				})
+				continue
+			}
+			restoreStatus.Restored++
+		}
+	}
+	for _, key := range keys {
+		u := &unstructured.Unstructured{Object: entries[key]}
+		if u.GetKind() != btpOperatorServiceInstance && u.GetKind() != btpOperatorServiceBinding {
+			restoreStatus.Failed = append(restoreStatus.Failed, v1alpha1.RestoreFailure{
+				Kind:      u.GetKind(),
+				Namespace: u.GetNamespace(),
+				Name:      u.GetName(),
+				Message:   "unsupported kind",
+			})
+		}
+	}
+
+	if err := r.updateRestoreStatus(ctx, cr, restoreStatus); err != nil {
+		return fmt.Errorf("while updating restore status: %w", err)
+	}
+	if len(restoreStatus.Failed) > 0 {
+		logger.Info(fmt.Sprintf("restored %d of %d resources, keeping the backup", restoreStatus.Restored, restoreStatus.Total))
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("restored all %d resources, deleting the backup", restoreStatus.Total))
+	for _, secret := range secrets {
+		if err := r.Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("while deleting backup Secret %s: %w", secret.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// restoreServiceResource creates the resource if it doesn't exist and sets the ID from the backup in its status, so that
+// sap-btp-service-operator adopts the existing resource in SAP BTP. Resources without the ID in the backup, or existing
+// with a different ID, can't be adopted.
+func (r *BtpOperatorReconciler) restoreServiceResource(ctx context.Context, u *unstructured.Unstructured) error {
+	idField := serviceIDField(u.GetKind())
+	id, _, _ := unstructured.NestedString(u.Object, "status", idField)
+	if id == "" {
+		return fmt.Errorf("the backup has no %s to adopt the resource with", idField)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(u.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}, existing)
+	if k8serrors.IsNotFound(err) {
+		toCreate := u.DeepCopy()
+		unstructured.RemoveNestedField(toCreate.Object, "status")
+		unstructured.RemoveNestedField(toCreate.Object, "metadata", "annotations", forceOrphanedAnnotation)
+		err = r.Create(ctx, toCreate)
+		if k8serrors.IsAlreadyExists(err) {
+			err = r.Get(ctx, client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}, existing)
+		} else {
+			existing = toCreate
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	existingID, _, _ := unstructured.NestedString(existing.Object, "status", idField)
+	if existingID == id {
+		return nil
+	}
+	if existingID != "" {
+		return fmt.Errorf("the resource exists with %s %s instead of %s from the backup", idField, existingID, id)
+	}
+	patch := client.MergeFrom(existing.DeepCopy())
+	if err := unstructured.SetNestedField(existing.Object, id, "status", idField); err != nil {
+		return err
+	}
+	if err := r.Status().Patch(ctx, existing, patch); err != nil {
+		return fmt.Errorf("while setting %s: %w", idField, err)
+	}
+	return nil
+}
+
+func (r *BtpOperatorReconciler) updateRestoreStatus(ctx context.Context, cr *v1alpha1.BtpOperator, restoreStatus *v1alpha1.RestoreStatus) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(cr), cr); err != nil {
+		return err
+	}
+	if cr.Status.Restore != nil {
+		previous := cr.Status.Restore.DeepCopy()
+		previous.LastUpdateTime = metav1.Time{}
+		if reflect.DeepEqual(previous, restoreStatus) {
+			return nil
+		}
+	}
+
+	restoreStatus.LastUpdateTime = metav1.Now()
+	cr.Status.Restore = restoreStatus
+	return r.Status().Update(ctx, cr)
+}
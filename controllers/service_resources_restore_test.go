@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBtpOperatorReconciler_restoreServiceResource(t *testing.T) {
+	newEntry := func(id string) *unstructured.Unstructured {
+		entry := newServiceResource(instanceGvk, "ns1", instanceName)
+		entry.SetAnnotations(map[string]string{forceOrphanedAnnotation: "2024-01-01T00:00:00Z"})
+		if id != "" {
+			require.NoError(t, unstructured.SetNestedField(entry.Object, id, "status", "instanceID"))
+		}
+		return entry
+	}
+	newReconciler := func(objs ...client.Object) *BtpOperatorReconciler {
+		return NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(objs...).
+			WithStatusSubresource(newServiceResource(instanceGvk, "", "")).Build(), clientgoscheme.Scheme, nil, nil)
+	}
+	getID := func(t *testing.T, reconciler *BtpOperatorReconciler) string {
+		restored := newServiceResource(instanceGvk, "ns1", instanceName)
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(restored), restored))
+		assert.NotContains(t, restored.GetAnnotations(), forceOrphanedAnnotation)
+		id, _, _ := unstructured.NestedString(restored.Object, "status", "instanceID")
+		return id
+	}
+
+	t.Run("should create the resource with the ID from the backup", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+
+		// when
+		err := reconciler.restoreServiceResource(context.Background(), newEntry("id-1"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "id-1", getID(t, reconciler))
+	})
+
+	t.Run("should set the ID on the existing resource without it", func(t *testing.T) {
+		// given
+		reconciler := newReconciler(newServiceResource(instanceGvk, "ns1", instanceName))
+
+		// when
+		err := reconciler.restoreServiceResource(context.Background(), newEntry("id-1"))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "id-1", getID(t, reconciler))
+	})
+
+	t.Run("should fail if the existing resource has a different ID", func(t *testing.T) {
+		// given
+		existing := newServiceResource(instanceGvk, "ns1", instanceName)
+		require.NoError(t, unstructured.SetNestedField(existing.Object, "id-2", "status", "instanceID"))
+		reconciler := newReconciler(existing)
+
+		// when
+		err := reconciler.restoreServiceResource(context.Background(), newEntry("id-1"))
+
+		// then
+		require.ErrorContains(t, err, "instanceID id-2")
+		assert.Equal(t, "id-2", getID(t, reconciler))
+	})
+
+	t.Run("should fail without the ID in the backup", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+
+		// when
+		err := reconciler.restoreServiceResource(context.Background(), newEntry(""))
+
+		// then
+		require.ErrorContains(t, err, "no instanceID")
+		restored := newServiceResource(instanceGvk, "ns1", instanceName)
+		assert.Error(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(restored), restored), "the resource isn't created")
+	})
+}
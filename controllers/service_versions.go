@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// serviceVersions resolves the versions of the ServiceInstance and ServiceBinding APIs from their CRDs. The storage version is used
+// if it's served, otherwise the first served version in the order of the CRD. The CRDs are read with the reader, which is the cached
+// client in the manager, so no requests to the API server are needed. The result is cached until the tracked CRDs change, and the
+// default version is used for a kind until its CRD exists.
+type serviceVersions struct {
+	reader   client.Reader
+	mu       sync.Mutex
+	resolved map[string]schema.GroupVersionKind
+}
+
+func newServiceVersions(reader client.Reader) *serviceVersions {
+	return &serviceVersions{reader: reader}
+}
+
+func (v *serviceVersions) gvk(kind string) schema.GroupVersionKind {
+	defaultGvk := schema.GroupVersionKind{Group: btpOperatorGroup, Version: btpOperatorApiVer, Kind: kind}
+	if v == nil || v.reader == nil {
+		return defaultGvk
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.resolved == nil {
+		resolved, err := v.resolve(context.Background())
+		if err != nil {
+			log.Log.Error(err, "while resolving service API versions, using the default version", "kind", kind, "version", btpOperatorApiVer)
+			return defaultGvk
+		}
+		v.resolved = resolved
+	}
+	if gvk, ok := v.resolved[kind]; ok {
+		return gvk
+	}
+	return defaultGvk
+}
+
+func (v *serviceVersions) invalidate() {
+	if v == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.resolved = nil
+}
+
+func (v *serviceVersions) resolve(ctx context.Context) (map[string]schema.GroupVersionKind, error) {
+	resolved := make(map[string]schema.GroupVersionKind)
+	for _, kind := range []string{btpOperatorServiceInstance, btpOperatorServiceBinding} {
+		gvk := schema.GroupVersionKind{Group: btpOperatorGroup, Kind: kind}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := v.reader.Get(ctx, client.ObjectKey{Name: crdName(gvk)}, crd); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("while getting CRD %s: %w", crdName(gvk), err)
+		}
+		if version := storageOrServedVersion(crd); version != "" {
+			gvk.Version = version
+			resolved[kind] = gvk
+		}
+	}
+	return resolved, nil
+}
+
+// storageOrServedVersion returns the storage version of the CRD if it's served, otherwise the first served version
+func storageOrServedVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	firstServed := ""
+	for _, version := range crd.Spec.Versions {
+		if !version.Served {
+			continue
+		}
+		if version.Storage {
+			return version.Name
+		}
+		if firstServed == "" {
+			firstServed = version.Name
+		}
+	}
+	return firstServed
+}
+
+func (r *BtpOperatorReconciler) serviceInstanceGvk() schema.GroupVersionKind {
+	return r.serviceVersions.gvk(btpOperatorServiceInstance)
+}
+
+func (r *BtpOperatorReconciler) serviceBindingGvk() schema.GroupVersionKind {
+	return r.serviceVersions.gvk(btpOperatorServiceBinding)
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestServiceVersions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	version := func(name string, served, storage bool) apiextensionsv1.CustomResourceDefinitionVersion {
+		return apiextensionsv1.CustomResourceDefinitionVersion{Name: name, Served: served, Storage: storage}
+	}
+	newCRD := func(gvk schema.GroupVersionKind, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		crd.SetName(crdName(gvk))
+		crd.Spec.Group = gvk.Group
+		crd.Spec.Versions = versions
+		return crd
+	}
+	newReader := func(objs ...client.Object) client.WithWatch {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	t.Run("should use the default version without the reader", func(t *testing.T) {
+		// given
+		var versions *serviceVersions
+
+		// then
+		assert.Equal(t, instanceGvk, versions.gvk(btpOperatorServiceInstance))
+	})
+
+	t.Run("should use the storage version", func(t *testing.T) {
+		// given
+		versions := newServiceVersions(newReader(
+			newCRD(instanceGvk, version("v2", true, false), version("v1", true, true)),
+			newCRD(bindingGvk, version("v1", true, false), version("v2", true, true)),
+		))
+
+		// then
+		assert.Equal(t, "v1", versions.gvk(btpOperatorServiceInstance).Version)
+		assert.Equal(t, "v2", versions.gvk(btpOperatorServiceBinding).Version)
+	})
+
+	t.Run("should fall back to the first served version if the storage version isn't served", func(t *testing.T) {
+		// given
+		versions := newServiceVersions(newReader(
+			newCRD(instanceGvk, version("v1alpha1", false, true), version("v2", true, false), version("v1", true, false)),
+		))
+
+		// then
+		assert.Equal(t, "v2", versions.gvk(btpOperatorServiceInstance).Version)
+		assert.Equal(t, bindingGvk, versions.gvk(btpOperatorServiceBinding), "the default version is used without the CRD")
+	})
+
+	t.Run("should use the default version when reading the CRDs fails", func(t *testing.T) {
+		// given
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+				return errors.New("reading failed")
+			},
+		}).Build()
+		versions := newServiceVersions(reader)
+
+		// then
+		assert.Equal(t, instanceGvk, versions.gvk(btpOperatorServiceInstance))
+		assert.Nil(t, versions.resolved)
+	})
+
+	t.Run("should resolve versions again after invalidation", func(t *testing.T) {
+		// given
+		reader := newReader(newCRD(instanceGvk, version("v1", true, true), version("v2", true, false)))
+		versions := newServiceVersions(reader)
+		assert.Equal(t, "v1", versions.gvk(btpOperatorServiceInstance).Version)
+
+		// when
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, reader.Get(context.Background(), client.ObjectKey{Name: crdName(instanceGvk)}, crd))
+		crd.Spec.Versions[0].Storage, crd.Spec.Versions[1].Storage = false, true
+		require.NoError(t, reader.Update(context.Background(), crd))
+
+		// then
+		assert.Equal(t, "v1", versions.gvk(btpOperatorServiceInstance).Version)
+
+		// when
+		versions.invalidate()
+
+		// then
+		assert.Equal(t, "v2", versions.gvk(btpOperatorServiceInstance).Version)
+	})
+}
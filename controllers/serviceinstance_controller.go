@@ -14,27 +14,32 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // ServiceInstanceReconciler reconciles a BtpOperator object in case of service instance changes
 type ServiceInstanceReconciler struct {
 	client.Client
 	*rest.Config
-	Scheme *runtime.Scheme
+	Scheme      *runtime.Scheme
+	instanceGvk schema.GroupVersionKind
+	bindingGvk  schema.GroupVersionKind
 }
 
 func NewServiceInstanceReconciler(client client.Client, scheme *runtime.Scheme) *ServiceInstanceReconciler {
 	return &ServiceInstanceReconciler{
-		Client: client,
-		Scheme: scheme,
+		Client:      client,
+		Scheme:      scheme,
+		instanceGvk: instanceGvk,
+		bindingGvk:  bindingGvk,
 	}
 }
 
@@ -43,7 +48,7 @@ func (r *ServiceInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	logger.Info("SI reconcile triggered")
 
 	list := &unstructured.UnstructuredList{}
-	list.SetGroupVersionKind(instanceGvk)
+	list.SetGroupVersionKind(r.instanceGvk)
 	err := r.List(ctx, list, client.InNamespace(corev1.NamespaceAll))
 	if err != nil {
 		return ctrl.Result{}, err
@@ -52,7 +57,7 @@ func (r *ServiceInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, nil
 	}
 
-	list.SetGroupVersionKind(bindingGvk)
+	list.SetGroupVersionKind(r.bindingGvk)
 	err = r.List(ctx, list, client.InNamespace(corev1.NamespaceAll))
 	if err != nil {
 		return ctrl.Result{}, err
@@ -85,23 +90,32 @@ func (r *ServiceInstanceReconciler) UpdateBtpOperatorStatus(ctx context.Context,
 	return r.Status().Update(ctx, cr)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *ServiceInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// newController creates a controller which is not added to the Manager, so that InstanceBindingControllerManager can start and stop it at runtime.
+// The name validation is skipped because a new controller with the same name is created every time the controller is enabled.
+// The controller watches and lists the given ServiceInstance and ServiceBinding versions.
+func (r *ServiceInstanceReconciler) newController(mgr ctrl.Manager, instanceGvk, bindingGvk schema.GroupVersionKind) (controller.Controller, error) {
 	r.Config = mgr.GetConfig()
+	r.instanceGvk = instanceGvk
+	r.bindingGvk = bindingGvk
+
+	skipNameValidation := true
+	c, err := controller.NewUnmanaged("serviceinstance", mgr, controller.Options{
+		Reconciler:         r,
+		RateLimiter:        workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](10*time.Millisecond, 1000*time.Second),
+		SkipNameValidation: &skipNameValidation,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	si := &unstructured.Unstructured{}
-	si.SetGroupVersionKind(instanceGvk)
-	sb := &unstructured.Unstructured{}
-	sb.SetGroupVersionKind(bindingGvk)
-
-	return ctrl.NewControllerManagedBy(mgr).
-		For(si,
-			builder.WithPredicates(r.deletionPredicate())).
-		Watches(sb,
-			&handler.EnqueueRequestForObject{},
-			builder.WithPredicates(r.deletionPredicate())).
-		WithOptions(controller.Options{RateLimiter: workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](10*time.Millisecond, 1000*time.Second)}).
-		Complete(r)
+	for _, gvk := range []schema.GroupVersionKind{instanceGvk, bindingGvk} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := c.Watch(source.Kind[client.Object](mgr.GetCache(), obj, &handler.EnqueueRequestForObject{}, r.deletionPredicate())); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 func (r *ServiceInstanceReconciler) deletionPredicate() predicate.Predicate {
@@ -80,6 +80,38 @@ var _ = Describe("Service Instance and Bindings controller", Ordered, func() {
 			})
 		})
 
+		When("Last Service Instance is removed after the controller is re-enabled", func() {
+			It("BTP Operator should be removed", func() {
+				// GIVEN
+				//  - create BTP operator
+				btpOperatorResource := createDefaultBtpOperator()
+				Expect(k8sClient.Create(ctx, btpOperatorResource)).To(Succeed())
+				Eventually(updateCh).Should(Receive(matchState(v1alpha1.StateReady)))
+				Expect(instanceBindingControllerManager.isSISBControllerRunning()).To(BeTrue())
+
+				//  - disable and enable the controller
+				instanceBindingControllerManager.DisableSISBController()
+				Expect(instanceBindingControllerManager.isSISBControllerRunning()).To(BeFalse())
+				instanceBindingControllerManager.EnableSISBController()
+				Expect(instanceBindingControllerManager.isSISBControllerRunning()).To(BeTrue())
+
+				//  - create Service Instance
+				siUnstructured := createResource(instanceGvk, kymaNamespace, serviceInstanceName)
+				ensureResourceExists(instanceGvk)
+
+				//  - trigger BTP operator deletion
+				Expect(k8sClient.Delete(ctx, btpOperatorResource)).To(Succeed())
+				Eventually(updateCh).Should(Receive(matchReadyCondition(v1alpha1.StateWarning, metav1.ConditionFalse, conditions.ServiceInstancesAndBindingsNotCleaned)))
+
+				// WHEN
+				Expect(k8sClient.Delete(ctx, siUnstructured)).To(Succeed())
+
+				// THEN
+				Eventually(updateCh).Should(Receive(matchDeleted()))
+				Expect(instanceBindingControllerManager.isSISBControllerRunning()).To(BeFalse())
+			})
+		})
+
 		When("Last Service Binding is removed", func() {
 			It("BTP Operator should be removed", func() {
 				// GIVEN
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// forceOrphanedAnnotation marks backup entries of resources whose finalizers were removed in the soft delete mode
+const forceOrphanedAnnotation = "operator.kyma-project.io/force-orphaned"
+
+// softDeletePendingError is returned by the soft delete when some resources are still being deleted by sap-btp-service-operator
+// within the SoftDeleteStuckThreshold. The soft delete is resumed at the stuck check after the SoftDeleteCheckInterval.
+type softDeletePendingError struct {
+	pending int
+}
+
+func (e *softDeletePendingError) Error() string {
+	return fmt.Sprintf("%d Service Instance(s) and Service Binding(s) are still being deleted", e.pending)
+}
+
+// deleteResourcesNotInDeletion sends delete requests for resources of the given GVKs in the given namespaces which are not in deletion yet
+func (r *BtpOperatorReconciler) deleteResourcesNotInDeletion(ctx context.Context, gvks []schema.GroupVersionKind, namespaces []string) error {
+	for _, gvk := range gvks {
+		for _, namespace := range namespaces {
+			list := r.GvkToList(gvk)
+			if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return fmt.Errorf("%w; could not list in soft delete", err)
+			}
+			for _, item := range list.Items {
+				if !item.GetDeletionTimestamp().IsZero() {
+					continue
+				}
+				if err := r.Delete(ctx, &item); client.IgnoreNotFound(err) != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// selectResourcesToOrphan returns resources of the given GVKs in the given namespaces that are stuck in deletion, in the order of the GVKs.
+// The second returned value is the number of the other resources, which are still being deleted by sap-btp-service-operator
+// within the SoftDeleteStuckThreshold or are not in deletion.
+func (r *BtpOperatorReconciler) selectResourcesToOrphan(ctx context.Context, gvks []schema.GroupVersionKind, namespaces []string) ([]unstructured.Unstructured, int, error) {
+	logger := log.FromContext(ctx)
+
+	toOrphan := make([]unstructured.Unstructured, 0)
+	pending := 0
+	for _, gvk := range gvks {
+		for _, namespace := range namespaces {
+			list := r.GvkToList(gvk)
+			if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				return nil, 0, fmt.Errorf("%w; could not list in soft delete", err)
+			}
+
+			for _, item := range list.Items {
+				if !r.isStuck(item, time.Now()) {
+					logger.Info("resource is still being deleted", "kind", item.GetKind(), "namespace", item.GetNamespace(), "name", item.GetName())
+					pending++
+					continue
+				}
+				toOrphan = append(toOrphan, item)
+			}
+		}
+	}
+
+	return toOrphan, pending, nil
+}
+
+// isStuck returns true if the resource has been in deletion longer than the SoftDeleteStuckThreshold
+// or sap-btp-service-operator reports a failure for it
+func (r *BtpOperatorReconciler) isStuck(item unstructured.Unstructured, now time.Time) bool {
+	deletionTimestamp := item.GetDeletionTimestamp()
+	if deletionTimestamp.IsZero() {
+		return false
+	}
+	if now.Sub(deletionTimestamp.Time) >= SoftDeleteStuckThreshold {
+		return true
+	}
+	_, failed := r.failureMessage(item)
+	return failed
+}
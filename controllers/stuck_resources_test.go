@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestBtpOperatorReconciler_isStuck(t *testing.T) {
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().Build(), clientgoscheme.Scheme, nil, nil)
+	defer func(threshold time.Duration) { SoftDeleteStuckThreshold = threshold }(SoftDeleteStuckThreshold)
+	SoftDeleteStuckThreshold = time.Minute * 10
+	now := time.Now()
+
+	newItem := func(deletedAgo time.Duration, conditions ...interface{}) unstructured.Unstructured {
+		item := unstructured.Unstructured{}
+		item.SetGroupVersionKind(instanceGvk)
+		item.SetName(instanceName)
+		item.SetFinalizers([]string{"test"})
+		if deletedAgo > 0 {
+			deletionTimestamp := metav1.NewTime(now.Add(-deletedAgo))
+			item.SetDeletionTimestamp(&deletionTimestamp)
+		}
+		if len(conditions) > 0 {
+			_ = unstructured.SetNestedSlice(item.Object, conditions, "status", "conditions")
+		}
+		return item
+	}
+	failedCondition := map[string]interface{}{
+		"type":    "Ready",
+		"status":  "False",
+		"reason":  "DeleteFailed",
+		"message": "deprovisioning failed",
+	}
+	succeededCondition := map[string]interface{}{
+		"type":   "Succeeded",
+		"status": "False",
+		"reason": "DeleteInProgress",
+	}
+
+	t.Run("should not select resource which is not in deletion", func(t *testing.T) {
+		assert.False(t, reconciler.isStuck(newItem(0, failedCondition), now))
+	})
+
+	t.Run("should not select resource in deletion within the threshold", func(t *testing.T) {
+		assert.False(t, reconciler.isStuck(newItem(time.Minute, succeededCondition), now))
+	})
+
+	t.Run("should select resource in deletion longer than the threshold", func(t *testing.T) {
+		assert.True(t, reconciler.isStuck(newItem(time.Minute*11), now))
+	})
+
+	t.Run("should select resource with failed deletion within the threshold", func(t *testing.T) {
+		assert.True(t, reconciler.isStuck(newItem(time.Minute, failedCondition), now))
+	})
+}
+
+func TestBtpOperatorReconciler_selectResourcesToOrphan(t *testing.T) {
+	// given
+	defer func(threshold time.Duration) { SoftDeleteStuckThreshold = threshold }(SoftDeleteStuckThreshold)
+	SoftDeleteStuckThreshold = time.Minute * 10
+	newDeletedResource := func(name string, deletedAgo time.Duration) *unstructured.Unstructured {
+		resource := newServiceResource(instanceGvk, "ns1", name)
+		resource.SetFinalizers([]string{"test"})
+		deletionTimestamp := metav1.NewTime(time.Now().Add(-deletedAgo))
+		resource.SetDeletionTimestamp(&deletionTimestamp)
+		return resource
+	}
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(
+		newDeletedResource("stuck", time.Minute*11),
+		newDeletedResource("in-deletion", time.Minute),
+		newServiceResource(instanceGvk, "ns1", "not-in-deletion"),
+	).Build(), clientgoscheme.Scheme, nil, nil)
+
+	// when
+	toOrphan, pending, err := reconciler.selectResourcesToOrphan(context.Background(), []schema.GroupVersionKind{instanceGvk}, []string{"ns1"})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, toOrphan, 1)
+	assert.Equal(t, "stuck", toOrphan[0].GetName())
+	assert.Equal(t, 2, pending)
+	notInDeletion := newServiceResource(instanceGvk, "ns1", "not-in-deletion")
+	assert.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(notInDeletion), notInDeletion), "selection doesn't delete resources")
+	inDeletion := newServiceResource(instanceGvk, "ns1", "in-deletion")
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(inDeletion), inDeletion))
+	assert.Equal(t, []string{"test"}, inDeletion.GetFinalizers())
+}
+
+func TestBtpOperatorReconciler_deleteResourcesNotInDeletion(t *testing.T) {
+	// given
+	inDeletion := newServiceResource(instanceGvk, "ns1", "in-deletion")
+	inDeletion.SetFinalizers([]string{"test"})
+	deletionTimestamp := metav1.NewTime(time.Now().Add(-time.Minute))
+	inDeletion.SetDeletionTimestamp(&deletionTimestamp)
+	withFinalizer := newServiceResource(instanceGvk, "ns1", "with-finalizer")
+	withFinalizer.SetFinalizers([]string{"test"})
+	var deleted []string
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(
+		inDeletion,
+		withFinalizer,
+		newServiceResource(instanceGvk, "ns1", "without-finalizer"),
+		newServiceResource(instanceGvk, "ns2", "other-namespace"),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			deleted = append(deleted, obj.GetName())
+			return c.Delete(ctx, obj, opts...)
+		},
+	}).Build(), clientgoscheme.Scheme, nil, nil)
+
+	// when
+	err := reconciler.deleteResourcesNotInDeletion(context.Background(), []schema.GroupVersionKind{instanceGvk}, []string{"ns1"})
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"with-finalizer", "without-finalizer"}, deleted)
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(withFinalizer), withFinalizer))
+	assert.False(t, withFinalizer.GetDeletionTimestamp().IsZero())
+}
+
+func TestBtpOperatorReconciler_softDelete(t *testing.T) {
+	// given
+	resource := newServiceResource(instanceGvk, "ns1", instanceName)
+	resource.SetFinalizers([]string{"test"})
+	reconciler := NewBtpOperatorReconciler(fake.NewClientBuilder().WithObjects(resource).Build(), clientgoscheme.Scheme, nil, nil)
+	listed := newServiceResource(instanceGvk, "ns1", instanceName)
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(listed), listed))
+
+	updated := listed.DeepCopy()
+	require.NoError(t, unstructured.SetNestedField(updated.Object, "updated", "spec", "externalName"))
+	require.NoError(t, reconciler.Update(context.Background(), updated))
+
+	// when
+	err := reconciler.softDelete(context.Background(), &v1alpha1.BtpOperator{}, []unstructured.Unstructured{*listed})
+
+	// then
+	require.NoError(t, err)
+	require.NoError(t, reconciler.Get(context.Background(), client.ObjectKeyFromObject(resource), resource))
+	assert.Empty(t, resource.GetFinalizers())
+	externalName, _, _ := unstructured.NestedString(resource.Object, "spec", "externalName")
+	assert.Equal(t, "updated", externalName, "the change made after listing is kept")
+}
@@ -54,6 +54,7 @@ var logger = logf.Log.WithName("suite_test")
 const (
 	hardDeleteTimeoutForAllTests         = time.Second * 1
 	deleteRequestTimeoutForAllTests      = time.Millisecond * 200
+	deletionRetryBackoffForAllTests      = time.Millisecond * 10
 	statusUpdateTimeoutForAllTests       = time.Millisecond * 200
 	statusUpdateCheckIntervalForAllTests = time.Millisecond * 20
 	testRsaKeyBits                       = 512
@@ -69,17 +70,18 @@ const (
 )
 
 var (
-	cfg                        *rest.Config
-	k8sClient                  client.Client
-	k8sClientFromManager       client.Client
-	k8sManager                 manager.Manager
-	testEnv                    *envtest.Environment
-	ctx                        context.Context
-	ctxForDeploymentController context.Context
-	cancel                     context.CancelFunc
-	cancelDeploymentController context.CancelFunc
-	reconciler                 *BtpOperatorReconciler
-	updateCh                   chan resourceUpdate = make(chan resourceUpdate, 1000)
+	cfg                              *rest.Config
+	k8sClient                        client.Client
+	k8sClientFromManager             client.Client
+	k8sManager                       manager.Manager
+	testEnv                          *envtest.Environment
+	ctx                              context.Context
+	ctxForDeploymentController       context.Context
+	cancel                           context.CancelFunc
+	cancelDeploymentController       context.CancelFunc
+	reconciler                       *BtpOperatorReconciler
+	instanceBindingControllerManager *InstanceBindingControllerManager
+	updateCh                         chan resourceUpdate = make(chan resourceUpdate, 1000)
 )
 
 func TestAPIs(t *testing.T) {
@@ -168,8 +170,8 @@ var _ = SynchronizedBeforeSuite(func() {
 	ctx, cancel = context.WithCancel(ctrl.SetupSignalHandler())
 
 	metrics := btpmanagermetrics.NewMetrics()
-	cleanupReconciler := NewInstanceBindingControllerManager(ctx, k8sManager.GetClient(), k8sManager.GetScheme(), cfg)
-	reconciler = NewBtpOperatorReconciler(k8sManager.GetClient(), k8sManager.GetScheme(), cleanupReconciler, metrics)
+	instanceBindingControllerManager = NewInstanceBindingControllerManager(ctx, k8sManager)
+	reconciler = NewBtpOperatorReconciler(k8sManager.GetClient(), k8sManager.GetScheme(), instanceBindingControllerManager, metrics)
 
 	k8sClientFromManager = k8sManager.GetClient()
 
@@ -191,6 +193,7 @@ var _ = SynchronizedBeforeSuite(func() {
 	} else {
 		DeleteRequestTimeout = deleteRequestTimeoutForAllTests
 	}
+	DeletionRetryBackoff = deletionRetryBackoffForAllTests
 	ChartPath = defaultChartPath
 	ResourcesPath = defaultResourcesPath
 	certs.SetRsaKeyBits(testRsaKeyBits)
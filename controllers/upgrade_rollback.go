@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The snapshot ConfigMap has the managed-by label, so it's cached and removed with module resources during deprovisioning
+const (
+	appliedResourcesSnapshotName = "btp-manager-applied-resources"
+	appliedResourcesSnapshotKey  = "resources.json"
+	failedChartVersionKey        = "failed-chart-version"
+	failedResourcesHashKey       = "failed-resources-hash"
+)
+
+// resourcesSnapshot holds the module resources applied in the last successful reconciliation with their chart version.
+// Secrets aren't stored, because they hold credentials and certificates, which are kept in the cluster during a rollback.
+// The chart version and the hash of the resources of an upgrade rolled back to the snapshot are stored with it, so that
+// the upgrade isn't retried until the resources to apply change.
+type resourcesSnapshot struct {
+	chartVersion       string
+	objects            []*unstructured.Unstructured
+	hash               string
+	failedChartVersion string
+	failedHash         string
+}
+
+// errUpgradeSkipped is the error of an upgrade skipped, because it was rolled back with the same resources to apply
+var errUpgradeSkipped = errors.New("the upgrade is skipped until the chart version or the module resources change")
+
+// upgradeRolledBackError is an error of an upgrade to a new chart version, after which the module resources of the
+// previous chart version were reapplied
+type upgradeRolledBackError struct {
+	fromVersion string
+	toVersion   string
+	err         error
+}
+
+func (e *upgradeRolledBackError) Error() string {
+	return fmt.Sprintf("upgrade from chart version %s to %s was rolled back: %s", e.fromVersion, e.toVersion, e.err)
+}
+
+func (e *upgradeRolledBackError) Unwrap() error {
+	return e.err
+}
+
+// newResourcesSnapshot returns copies of the resources to apply without Secrets and fields set by the API server. The resources
+// are copied through JSON, because the CA bundles of webhook configurations are byte slices, which DeepCopy doesn't support.
+func (r *BtpOperatorReconciler) newResourcesSnapshot(us []*unstructured.Unstructured) (*resourcesSnapshot, error) {
+	snapshot := &resourcesSnapshot{chartVersion: chartVersionOf(us), objects: make([]*unstructured.Unstructured, 0, len(us))}
+	for _, u := range us {
+		if u.GetKind() == secretKind {
+			continue
+		}
+		data, err := json.Marshal(u.Object)
+		if err != nil {
+			return nil, fmt.Errorf("while copying %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(data, &obj.Object); err != nil {
+			return nil, fmt.Errorf("while copying %s %s: %w", u.GetKind(), u.GetName(), err)
+		}
+		obj.SetResourceVersion("")
+		obj.SetUID("")
+		obj.SetManagedFields(nil)
+		unstructured.RemoveNestedField(obj.Object, "status")
+		snapshot.objects = append(snapshot.objects, obj)
+	}
+	data, err := json.Marshal(snapshot.objects)
+	if err != nil {
+		return nil, fmt.Errorf("while hashing resources: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	snapshot.hash = hex.EncodeToString(sum[:])
+	return snapshot, nil
+}
+
+// isRolledBackUpgrade returns true if the upgrade to the resources was already rolled back to the snapshot
+func (s *resourcesSnapshot) isRolledBackUpgrade(upgrade *resourcesSnapshot) bool {
+	return s != nil && s.failedHash != "" && s.failedHash == upgrade.hash
+}
+
+// getResourcesSnapshot returns the snapshot of the module resources applied in the last successful reconciliation, or nil if there is none
+func (r *BtpOperatorReconciler) getResourcesSnapshot(ctx context.Context) (*resourcesSnapshot, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ChartNamespace, Name: appliedResourcesSnapshotName}, cm); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("while getting applied module resources snapshot: %w", err)
+	}
+
+	objects := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal([]byte(cm.Data[appliedResourcesSnapshotKey]), &objects); err != nil {
+		return nil, fmt.Errorf("while reading applied module resources snapshot: %w", err)
+	}
+	snapshot := &resourcesSnapshot{
+		chartVersion:       cm.GetLabels()[chartVersionKey],
+		objects:            make([]*unstructured.Unstructured, 0, len(objects)),
+		failedChartVersion: cm.GetAnnotations()[failedChartVersionKey],
+		failedHash:         cm.GetAnnotations()[failedResourcesHashKey],
+	}
+	for _, obj := range objects {
+		snapshot.objects = append(snapshot.objects, &unstructured.Unstructured{Object: obj})
+	}
+	return snapshot, nil
+}
+
+// storeResourcesSnapshot stores the snapshot in the ConfigMap labeled with its chart version and annotated with the rolled back upgrade
+func (r *BtpOperatorReconciler) storeResourcesSnapshot(ctx context.Context, snapshot *resourcesSnapshot) error {
+	objects := make([]map[string]interface{}, 0, len(snapshot.objects))
+	for _, u := range snapshot.objects {
+		objects = append(objects, u.Object)
+	}
+	out, err := json.Marshal(objects)
+	if err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       configMapKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      appliedResourcesSnapshotName,
+			Namespace: ChartNamespace,
+			Labels:    map[string]string{managedByLabelKey: operatorName, chartVersionKey: snapshot.chartVersion},
+		},
+		Data: map[string]string{appliedResourcesSnapshotKey: string(out)},
+	}
+	if snapshot.failedHash != "" {
+		cm.SetAnnotations(map[string]string{failedChartVersionKey: snapshot.failedChartVersion, failedResourcesHashKey: snapshot.failedHash})
+	}
+
+	return r.Patch(ctx, cm, client.Apply, client.ForceOwnership, client.FieldOwner(operatorName))
+}
+
+// rollBackFailedUpgrade reapplies the module resources of the snapshot if applying the resources of the upgrade to another chart version
+// failed, stores the upgrade as rolled back with the snapshot, and returns the upgradeRolledBackError. Otherwise, or if the rollback fails too,
+// it returns the error of the upgrade.
+func (r *BtpOperatorReconciler) rollBackFailedUpgrade(ctx context.Context, snapshot, upgrade *resourcesSnapshot, err error) error {
+	logger := log.FromContext(ctx)
+
+	if snapshot == nil || snapshot.chartVersion == upgrade.chartVersion {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("rolling back module resources from chart version %s to %s", upgrade.chartVersion, snapshot.chartVersion))
+	if rollbackErr := r.rollBack(ctx, snapshot, upgrade.objects); rollbackErr != nil {
+		logger.Error(rollbackErr, "while rolling back module resources")
+		return fmt.Errorf("%w, and rollback to chart version %s failed: %s", err, snapshot.chartVersion, rollbackErr)
+	}
+
+	snapshot.failedChartVersion, snapshot.failedHash = upgrade.chartVersion, upgrade.hash
+	if storeErr := r.storeResourcesSnapshot(ctx, snapshot); storeErr != nil {
+		logger.Error(storeErr, "while storing rolled back upgrade, the upgrade will be retried")
+	}
+	return &upgradeRolledBackError{fromVersion: snapshot.chartVersion, toVersion: upgrade.chartVersion, err: err}
+}
+
+// rollBack applies the module resources of the snapshot and deletes the ones, which only the failed chart version has.
+// CRDs of the failed chart version are kept, because deleting them would delete their custom resources. The Secrets with
+// certificates are kept too, so the CA bundles of webhook configurations are set from the current CA Secret.
+func (r *BtpOperatorReconciler) rollBack(ctx context.Context, snapshot *resourcesSnapshot, failed []*unstructured.Unstructured) error {
+	// the deadline of the failed upgrade may already be exceeded, so the readiness waits of the rollback share their own deadline
+	ctx, cancel := context.WithTimeout(ctx, ReadyTimeout)
+	defer cancel()
+
+	if err := r.prepareWebhooksConfigurationsReconciliationData(ctx, &snapshot.objects, nil); err != nil {
+		return fmt.Errorf("while setting CA bundles of webhook configurations: %w", err)
+	}
+	if err := r.applyOrUpdateResources(ctx, snapshot.objects); err != nil {
+		return err
+	}
+
+	previous := make(map[string]bool, len(snapshot.objects))
+	for _, u := range snapshot.objects {
+		previous[snapshotKey(u)] = true
+	}
+	added := make([]*unstructured.Unstructured, 0)
+	for _, u := range failed {
+		if u.GetKind() == secretKind || u.GetKind() == customResourceDefinitionKind || previous[snapshotKey(u)] {
+			continue
+		}
+		added = append(added, u)
+	}
+	if err := r.deleteResources(ctx, added); err != nil {
+		return err
+	}
+
+	return r.waitForResourcesReadiness(ctx, snapshot.objects)
+}
+
+// chartVersionOf returns the chart version, which the resources are labeled with
+func chartVersionOf(us []*unstructured.Unstructured) string {
+	for _, u := range us {
+		if version := u.GetLabels()[chartVersionKey]; version != "" {
+			return version
+		}
+	}
+	return ""
+}
+
+func snapshotKey(u *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", u.GroupVersionKind().GroupKind(), u.GetNamespace(), u.GetName())
+}
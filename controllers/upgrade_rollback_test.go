@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/internal/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRollBackFailedUpgrade(t *testing.T) {
+	// given
+	defaultReadyTimeout := ReadyTimeout
+	ReadyTimeout = time.Second * 2
+	defer func() { ReadyTimeout = defaultReadyTimeout }()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+
+	newObject := func(apiVersion, kind, name, chartVersion string, fields map[string]interface{}) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: fields}
+		if u.Object == nil {
+			u.Object = map[string]interface{}{}
+		}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetName(name)
+		if kind != customResourceDefinitionKind && kind != ValidatingWebhookConfiguration {
+			u.SetNamespace(ChartNamespace)
+		}
+		u.SetLabels(map[string]string{managedByLabelKey: operatorName, chartVersionKey: chartVersion})
+		return u
+	}
+	newDeployment := func(chartVersion, image string) *unstructured.Unstructured {
+		return newObject("apps/v1", deploymentKind, "operator", chartVersion, map[string]interface{}{"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "operator"}},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "operator"}},
+				"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "manager", "image": image}}},
+			},
+		}})
+	}
+	newWebhookConfiguration := func(chartVersion string) *unstructured.Unstructured {
+		return newObject("admissionregistration.k8s.io/v1", ValidatingWebhookConfiguration, validatingWebhookName, chartVersion, map[string]interface{}{
+			"webhooks": []interface{}{map[string]interface{}{"name": "vserviceinstance.kb.io", "clientConfig": map[string]interface{}{"caBundle": "b2xkIENB"}}},
+		})
+	}
+	previous := []*unstructured.Unstructured{
+		newObject("v1", configMapKind, btpServiceOperatorConfigMap, "1.0.0", map[string]interface{}{"data": map[string]interface{}{"version": "previous"}}),
+		newObject("v1", secretKind, btpServiceOperatorSecret, "1.0.0", nil),
+		newDeployment("1.0.0", "operator:v1"),
+		newWebhookConfiguration("1.0.0"),
+	}
+	failed := []*unstructured.Unstructured{
+		newObject("v1", configMapKind, btpServiceOperatorConfigMap, "2.0.0", map[string]interface{}{"data": map[string]interface{}{"version": "failed"}}),
+		newObject("v1", configMapKind, "added", "2.0.0", nil),
+		newObject("v1", secretKind, btpServiceOperatorSecret, "2.0.0", nil),
+		newObject("v1", secretKind, "added", "2.0.0", nil),
+		newObject("apiextensions.k8s.io/v1", customResourceDefinitionKind, "addeds.services.cloud.sap.com", "2.0.0", nil),
+		newDeployment("2.0.0", "operator:v2"),
+		newWebhookConfiguration("2.0.0"),
+	}
+	upgradeErr := errors.New("timed out while waiting for resources readiness: Deployment operator in namespace kyma-system readiness timeout reached")
+
+	newReconciler := func() *BtpOperatorReconciler {
+		caSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: CaSecret, Namespace: ChartNamespace},
+			Data:       map[string][]byte{"ca.crt": []byte("current CA")},
+		}
+		available := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: ChartNamespace},
+			Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+				{Type: deploymentProgressingConditionType, Status: "True"},
+				{Type: deploymentAvailableConditionType, Status: "True"},
+			}},
+		}
+		existing := []client.Object{caSecret, available}
+		for _, u := range failed {
+			if u.GetKind() != deploymentKind {
+				existing = append(existing, u.DeepCopy())
+			}
+		}
+		return NewBtpOperatorReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing...).WithInterceptorFuncs(interceptor.Funcs{
+			// the fake client doesn't support server-side apply
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				preExisting := obj.DeepCopyObject().(client.Object)
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), preExisting); err != nil {
+					if k8serrors.IsNotFound(err) {
+						return c.Create(ctx, obj)
+					}
+					return err
+				}
+				obj.SetResourceVersion(preExisting.GetResourceVersion())
+				return c.Update(ctx, obj)
+			},
+		}).WithStatusSubresource(&appsv1.Deployment{}).Build(), scheme, nil, nil)
+	}
+
+	t.Run("should reapply module resources of the previous chart version", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+		snapshot, err := reconciler.newResourcesSnapshot(previous)
+		require.NoError(t, err)
+		require.NoError(t, reconciler.storeResourcesSnapshot(context.Background(), snapshot))
+		snapshot, err = reconciler.getResourcesSnapshot(context.Background())
+		require.NoError(t, err)
+		upgrade, err := reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+
+		// when
+		err = reconciler.rollBackFailedUpgrade(context.Background(), snapshot, upgrade, upgradeErr)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "upgrade from chart version 1.0.0 to 2.0.0 was rolled back: "+upgradeErr.Error(), err.Error())
+		assert.Equal(t, conditions.UpgradeRolledBack, reasonForError(err, conditions.ReconcileFailed))
+
+		deployment := &appsv1.Deployment{}
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: "operator", Namespace: ChartNamespace}, deployment))
+		assert.Equal(t, "operator:v1", deployment.Spec.Template.Spec.Containers[0].Image)
+		assert.Equal(t, "1.0.0", deployment.Labels[chartVersionKey])
+		configMap := &corev1.ConfigMap{}
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: btpServiceOperatorConfigMap, Namespace: ChartNamespace}, configMap))
+		assert.Equal(t, "previous", configMap.Data["version"])
+		err = reconciler.Get(context.Background(), client.ObjectKey{Name: "added", Namespace: ChartNamespace}, &corev1.ConfigMap{})
+		assert.True(t, k8serrors.IsNotFound(err))
+
+		assert.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: "added", Namespace: ChartNamespace}, &corev1.Secret{}))
+		assert.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: "addeds.services.cloud.sap.com"}, &apiextensionsv1.CustomResourceDefinition{}))
+		secret := &corev1.Secret{}
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: btpServiceOperatorSecret, Namespace: ChartNamespace}, secret))
+		assert.Equal(t, "2.0.0", secret.Labels[chartVersionKey])
+
+		webhookConfiguration := &unstructured.Unstructured{}
+		webhookConfiguration.SetGroupVersionKind(newWebhookConfiguration("").GroupVersionKind())
+		require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: validatingWebhookName}, webhookConfiguration))
+		webhooks, _, _ := unstructured.NestedSlice(webhookConfiguration.Object, "webhooks")
+		caBundle, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle")
+		assert.Equal(t, "Y3VycmVudCBDQQ==", caBundle)
+
+		stored, err := reconciler.getResourcesSnapshot(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", stored.chartVersion)
+		assert.Equal(t, "2.0.0", stored.failedChartVersion)
+		assert.True(t, stored.isRolledBackUpgrade(upgrade))
+	})
+
+	t.Run("should retry the rolled back upgrade only with other resources", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+		snapshot, err := reconciler.newResourcesSnapshot(previous)
+		require.NoError(t, err)
+		upgrade, err := reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+		snapshot.failedChartVersion, snapshot.failedHash = upgrade.chartVersion, upgrade.hash
+		require.NoError(t, reconciler.storeResourcesSnapshot(context.Background(), snapshot))
+
+		// when
+		stored, err := reconciler.getResourcesSnapshot(context.Background())
+		require.NoError(t, err)
+		changed := append([]*unstructured.Unstructured{}, failed...)
+		changed[len(changed)-2] = newDeployment("2.0.0", "operator:v2.1")
+		changedUpgrade, err := reconciler.newResourcesSnapshot(changed)
+		require.NoError(t, err)
+
+		// then
+		assert.True(t, stored.isRolledBackUpgrade(upgrade))
+		assert.False(t, stored.isRolledBackUpgrade(changedUpgrade))
+
+		// when
+		snapshot, err = reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+		require.NoError(t, reconciler.storeResourcesSnapshot(context.Background(), snapshot))
+		stored, err = reconciler.getResourcesSnapshot(context.Background())
+		require.NoError(t, err)
+
+		// then
+		assert.False(t, stored.isRolledBackUpgrade(upgrade), "the successful reconciliation clears the rolled back upgrade")
+		assert.False(t, (*resourcesSnapshot)(nil).isRolledBackUpgrade(upgrade))
+	})
+
+	t.Run("should not roll back without a snapshot of another chart version", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+
+		sameVersionSnapshot, err := reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+		upgrade, err := reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+
+		for _, snapshot := range []*resourcesSnapshot{nil, sameVersionSnapshot} {
+			// when
+			err := reconciler.rollBackFailedUpgrade(context.Background(), snapshot, upgrade, upgradeErr)
+
+			// then
+			assert.Equal(t, upgradeErr, err)
+			assert.Equal(t, conditions.ReconcileFailed, reasonForError(err, conditions.ReconcileFailed))
+			configMap := &corev1.ConfigMap{}
+			require.NoError(t, reconciler.Get(context.Background(), client.ObjectKey{Name: "added", Namespace: ChartNamespace}, configMap))
+		}
+	})
+
+	t.Run("should report the upgrade error if the rollback fails", func(t *testing.T) {
+		// given
+		reconciler := newReconciler()
+		snapshot, err := reconciler.newResourcesSnapshot(previous)
+		require.NoError(t, err)
+		snapshot.objects = append(snapshot.objects, newDeployment("1.0.0", "operator:v1"))
+		snapshot.objects[len(snapshot.objects)-1].SetName("never-available")
+		upgrade, err := reconciler.newResourcesSnapshot(failed)
+		require.NoError(t, err)
+
+		// when
+		err = reconciler.rollBackFailedUpgrade(context.Background(), snapshot, upgrade, upgradeErr)
+
+		// then
+		require.Error(t, err)
+		assert.ErrorIs(t, err, upgradeErr)
+		assert.Contains(t, err.Error(), "rollback to chart version 1.0.0 failed: ")
+		assert.Equal(t, conditions.ReconcileFailed, reasonForError(err, conditions.ReconcileFailed))
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/kyma-project/btp-manager/internal/conditions"
 
 	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/kyma-project/btp-manager/internal/manifest"
 	"github.com/kyma-project/btp-manager/internal/ymlutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -528,6 +529,10 @@ func createChartOrResources(src, dst string, includeWebhooks bool) error {
 	}
 
 	return filepath.WalkDir(dst, func(path string, de fs.DirEntry, err error) error {
+		if err == nil && de.Name() == manifest.ChecksumsFile {
+			// tests modify the copied manifests, which are verified only if the checksums file exists
+			return os.Remove(path)
+		}
 		if !includeWebhooks {
 			return nil
 		}
@@ -617,6 +622,10 @@ func checkIfNoBtpResourceExists() {
 
 	found := false
 	for _, gvk := range gvks {
+		// CRDs are kept by default, see checkIfServiceCRDsExist
+		if gvk.Kind == customResourceDefinitionKind {
+			continue
+		}
 		list := &unstructured.UnstructuredList{}
 		list.SetGroupVersionKind(schema.GroupVersionKind{
 			Version: gvk.Version,
@@ -636,6 +645,14 @@ func checkIfNoBtpResourceExists() {
 	Expect(found).To(BeFalse())
 }
 
+func checkIfServiceCRDsExist(expected bool) {
+	for _, gvk := range []schema.GroupVersionKind{instanceGvk, bindingGvk} {
+		Eventually(func() (bool, error) {
+			return reconciler.crdExists(ctx, gvk)
+		}).WithTimeout(k8sOpsTimeout).WithPolling(k8sOpsPollingInterval).Should(Equal(expected))
+	}
+}
+
 func canIgnoreErr(err error) bool {
 	return k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) || k8serrors.IsMethodNotSupported(err)
 }
@@ -786,17 +803,58 @@ func matchState(state v1alpha1.State) gomegatypes.GomegaMatcher {
 	})
 }
 
+// matchReadyCondition matches the CR update with the Ready condition as the only condition, except for the ServiceCRDsAvailable
+// condition, which is set independently of the state, see haveServiceCRDsCondition
 func matchReadyCondition(state v1alpha1.State, status metav1.ConditionStatus, reason conditions.Reason) gomegatypes.GomegaMatcher {
 	return MatchFields(IgnoreExtras, Fields{
 		"Action": Equal(resourceUpdated),
 		"Cr": PointTo(MatchFields(IgnoreExtras, Fields{
 			"Status": MatchFields(IgnoreExtras, Fields{
 				"State": Equal(state),
-				"Conditions": ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Conditions": WithTransform(withoutServiceCRDsCondition, ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":   Equal(conditions.ReadyType),
 					"Reason": Equal(string(reason)),
 					"Status": Equal(status),
-				}))),
+				})))),
+			}),
+		})),
+	})
+}
+
+func withoutServiceCRDsCondition(crConditions []*metav1.Condition) []*metav1.Condition {
+	filtered := make([]*metav1.Condition, 0, len(crConditions))
+	for _, condition := range crConditions {
+		if condition.Type != conditions.ServiceCRDsAvailableType {
+			filtered = append(filtered, condition)
+		}
+	}
+	return filtered
+}
+
+func haveServiceCRDsCondition(status metav1.ConditionStatus, reason conditions.Reason) gomegatypes.GomegaMatcher {
+	return PointTo(MatchFields(IgnoreExtras, Fields{
+		"Status": MatchFields(IgnoreExtras, Fields{
+			"Conditions": ContainElement(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":   Equal(conditions.ServiceCRDsAvailableType),
+				"Reason": Equal(string(reason)),
+				"Status": Equal(status),
+			}))),
+		}),
+	}))
+}
+
+func matchDeprovisioningProgress(namespace string, instances, bindings int) gomegatypes.GomegaMatcher {
+	return MatchFields(IgnoreExtras, Fields{
+		"Action": Equal(resourceUpdated),
+		"Cr": PointTo(MatchFields(IgnoreExtras, Fields{
+			"Status": MatchFields(IgnoreExtras, Fields{
+				"Deprovisioning": PointTo(MatchFields(IgnoreExtras, Fields{
+					"Namespaces": ContainElement(MatchFields(IgnoreExtras, Fields{
+						"Namespace":        Equal(namespace),
+						"ServiceInstances": Equal(instances),
+						"ServiceBindings":  Equal(bindings),
+					})),
+				})),
 			}),
 		})),
 	})
@@ -887,3 +945,11 @@ func (r *deploymentReconciler) watchBtpOperatorDeploymentPredicate() predicate.T
 		},
 	}
 }
+
+func getBackupEntries() map[string]map[string]interface{} {
+	secrets := &corev1.SecretList{}
+	Expect(k8sClient.List(ctx, secrets, client.InNamespace(kymaNamespace), backupLabelFilter)).To(Succeed())
+	entries, err := reconciler.readBackupEntries(secrets.Items)
+	Expect(err).To(BeNil())
+	return entries
+}
@@ -30,9 +30,13 @@ func getRandomInt() *big.Int {
 }
 
 func GenerateSelfSignedCertificate(expiration time.Time) ([]byte, []byte, error) {
+	return GenerateSelfSignedCertificateForDNSNames(expiration, getDns())
+}
+
+func GenerateSelfSignedCertificateForDNSNames(expiration time.Time, dnsNames []string) ([]byte, []byte, error) {
 	newCertificateTemplate := &x509.Certificate{
 		SerialNumber:          getRandomInt(),
-		DNSNames:              getDns(),
+		DNSNames:              dnsNames,
 		NotBefore:             time.Now().UTC(),
 		NotAfter:              expiration,
 		IsCA:                  true,
@@ -71,9 +75,13 @@ func GenerateSelfSignedCertificate(expiration time.Time) ([]byte, []byte, error)
 }
 
 func GenerateSignedCertificate(expiration time.Time, sourceCertificate, sourcePrivateKey []byte) ([]byte, []byte, error) {
+	return GenerateSignedCertificateForDNSNames(expiration, sourceCertificate, sourcePrivateKey, getDns())
+}
+
+func GenerateSignedCertificateForDNSNames(expiration time.Time, sourceCertificate, sourcePrivateKey []byte, dnsNames []string) ([]byte, []byte, error) {
 	newCertificateTemplate := &x509.Certificate{
 		SerialNumber: getRandomInt(),
-		DNSNames:     getDns(),
+		DNSNames:     dnsNames,
 		NotBefore:    time.Now().UTC(),
 		NotAfter:     expiration,
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
@@ -34,12 +34,31 @@ const (
 	StoringChartDetailsFailed             Reason = "StoringChartDetailsFailed"
 	GettingConfigMapFailed                Reason = "GettingConfigMapFailed"
 	ProvisioningFailed                    Reason = "ProvisioningFailed"
+	Orphaning                             Reason = "Orphaning"
+	ManifestVerificationFailed            Reason = "ManifestVerificationFailed"
+	ManifestValidationFailed              Reason = "ManifestValidationFailed"
+	UpgradeRolledBack                     Reason = "UpgradeRolledBack"
+	ServiceResourcesKept                  Reason = "ServiceResourcesKept"
 )
 
 // gophers_reasons_section_end
 
 const (
-	ReadyType = "Ready"
+	ReadyType                  = "Ready"
+	ServiceCRDsAvailableType   = "ServiceCRDsAvailable"
+	ModuleResourcesPatchedType = "ModuleResourcesPatched"
+)
+
+// Reasons of the ServiceCRDsAvailable condition, which doesn't change the state of the CR
+const (
+	ServiceCRDsEstablished    Reason = "ServiceCRDsEstablished"
+	ServiceCRDsNotEstablished Reason = "ServiceCRDsNotEstablished"
+)
+
+// Reasons of the ModuleResourcesPatched condition, which doesn't change the state of the CR
+const (
+	PatchesApplied Reason = "PatchesApplied"
+	PatchFailed    Reason = "PatchFailed"
 )
 
 type Metadata struct {
@@ -73,6 +92,11 @@ var Reasons = map[Reason]Metadata{
 	GettingConfigMapFailed:                {Status: metav1.ConditionFalse, State: v1alpha1.StateError},      //Error;Getting Config Map failed
 	ProvisioningFailed:                    {Status: metav1.ConditionFalse, State: v1alpha1.StateError},      //Error;Provisioning failed
 	ServiceInstancesAndBindingsNotCleaned: {Status: metav1.ConditionFalse, State: v1alpha1.StateWarning},    //Warning;Deprovisioning blocked because of ServiceInstances and/or ServiceBindings existence
+	Orphaning:                             {Status: metav1.ConditionFalse, State: v1alpha1.StateDeleting},   //Deleting;Removing module components and leaving ServiceInstances and ServiceBindings
+	ManifestVerificationFailed:            {Status: metav1.ConditionFalse, State: v1alpha1.StateError},      //Error;Module resources manifests do not match their checksums or signature
+	ManifestValidationFailed:              {Status: metav1.ConditionFalse, State: v1alpha1.StateError},      //Error;Module resources would be rejected by the API server
+	UpgradeRolledBack:                     {Status: metav1.ConditionFalse, State: v1alpha1.StateError},      //Error;Upgrade failed and module resources were rolled back to the previous chart version
+	ServiceResourcesKept:                  {Status: metav1.ConditionFalse, State: v1alpha1.StateWarning},    //Warning;CRDs kept with remaining ServiceInstances and/or ServiceBindings after deprovisioning
 }
 
 // gophers_metadata_section_end
@@ -110,3 +134,17 @@ func SetStatusCondition(conditions *[]*metav1.Condition, newCondition metav1.Con
 		(*conditions)[conditionsCnt] = &conditionsArray[conditionsCnt]
 	}
 }
+
+// RemoveStatusCondition removes the condition of the given type and returns true if it existed
+func RemoveStatusCondition(conditions *[]*metav1.Condition, conditionType string) bool {
+	remaining := make([]*metav1.Condition, 0, len(*conditions))
+	for _, condition := range *conditions {
+		if condition != nil && condition.Type == conditionType {
+			continue
+		}
+		remaining = append(remaining, condition)
+	}
+	removed := len(remaining) != len(*conditions)
+	*conditions = remaining
+	return removed
+}
@@ -69,3 +69,26 @@ func TestSetStatusCondition(t *testing.T) {
 		assert.Equal(t, "MissingSecret", btpOperator.Status.Conditions[0].Reason)
 	})
 }
+
+func TestRemoveStatusCondition(t *testing.T) {
+	t.Run("should remove the condition of the given type", func(t *testing.T) {
+		btpOperator := &v1alpha1.BtpOperator{}
+		SetStatusCondition(&btpOperator.Status.Conditions, *ConditionFromExistingReason("ReconcileSucceeded", "Ready to process"))
+		SetStatusCondition(&btpOperator.Status.Conditions, metav1.Condition{Type: ModuleResourcesPatchedType, Status: metav1.ConditionTrue, Reason: string(PatchesApplied)})
+
+		removed := RemoveStatusCondition(&btpOperator.Status.Conditions, ModuleResourcesPatchedType)
+
+		assert.True(t, removed)
+		assert.Equal(t, 1, len(btpOperator.Status.Conditions))
+		assert.Equal(t, "Ready", btpOperator.Status.Conditions[0].Type)
+	})
+	t.Run("should keep conditions without the given type", func(t *testing.T) {
+		btpOperator := &v1alpha1.BtpOperator{}
+		SetStatusCondition(&btpOperator.Status.Conditions, *ConditionFromExistingReason("ReconcileSucceeded", "Ready to process"))
+
+		removed := RemoveStatusCondition(&btpOperator.Status.Conditions, ModuleResourcesPatchedType)
+
+		assert.False(t, removed)
+		assert.Equal(t, 1, len(btpOperator.Status.Conditions))
+	})
+}
@@ -0,0 +1,34 @@
+package fsutils
+
+import (
+	"io/fs"
+	"os"
+)
+
+// OSFS is the OS filesystem as fs.FS, which opens files by their OS paths. Unlike os.DirFS, it accepts absolute paths
+// and relative paths with "..", so paths from flags and the configuration can be used as they are.
+type OSFS struct{}
+
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (OSFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// OrOS returns the filesystem, or the OS filesystem if it's nil
+func OrOS(fsys fs.FS) fs.FS {
+	if fsys == nil {
+		return OSFS{}
+	}
+	return fsys
+}
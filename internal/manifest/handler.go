@@ -1,22 +1,40 @@
 package manifest
 
 import (
+	"crypto/ed25519"
 	"fmt"
-	"os"
-	"regexp"
+	"io/fs"
+	"path"
 	"strings"
 
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"github.com/kyma-project/btp-manager/internal/ymlutils"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 )
 
 type Handler struct {
-	Scheme               *runtime.Scheme
+	Scheme *runtime.Scheme
+	// FS is the filesystem with manifests, the OS filesystem is used if it's nil
+	FS fs.FS
+	// PublicKey is the ed25519 key, which the checksums of manifests in a directory must be signed with. If it's nil,
+	// the checksums are verified only if the directory has the checksums file.
+	PublicKey            ed25519.PublicKey
 	manifestDeserializer runtime.Decoder
 }
 
+// WithFS returns a copy of the handler, which reads manifests from the filesystem
+func (h *Handler) WithFS(fsys fs.FS) *Handler {
+	return &Handler{Scheme: h.Scheme, FS: fsys, PublicKey: h.PublicKey, manifestDeserializer: h.manifestDeserializer}
+}
+
+// CollectObjectsFromDir verifies the manifests in the directory against the checksums file and creates objects from them
 func (h *Handler) CollectObjectsFromDir(resourcesPath string) ([]runtime.Object, error) {
+	if err := h.verifyDir(fsutils.OrOS(h.FS), resourcesPath); err != nil {
+		return nil, err
+	}
+
 	manifests, err := h.GetManifestsFromDir(resourcesPath)
 	if err != nil {
 		return nil, fmt.Errorf("while getting manifests from %s directory: %w", resourcesPath, err)
@@ -31,7 +49,7 @@ func (h *Handler) CollectObjectsFromDir(resourcesPath string) ([]runtime.Object,
 }
 
 func (h *Handler) GetManifestsFromDir(resourcesPath string) ([]string, error) {
-	files, err := os.ReadDir(resourcesPath)
+	files, err := fs.ReadDir(fsutils.OrOS(h.FS), resourcesPath)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +59,7 @@ func (h *Handler) GetManifestsFromDir(resourcesPath string) ([]string, error) {
 		if !isYamlFile(file.Name()) {
 			continue
 		}
-		manifestsFromSingleYamlFile, err := h.GetManifestsFromYaml(fmt.Sprintf("%s%c%s", resourcesPath, os.PathSeparator, file.Name()))
+		manifestsFromSingleYamlFile, err := h.GetManifestsFromYaml(path.Join(resourcesPath, file.Name()))
 		if err != nil {
 			return nil, fmt.Errorf("while getting manifests from YAML: %w", err)
 		}
@@ -55,35 +73,17 @@ func isYamlFile(fileName string) bool {
 	return strings.HasSuffix(fileName, ".yml") || strings.HasSuffix(fileName, ".yaml")
 }
 
+// GetManifestsFromYaml returns the documents of the YAML file as separate manifests, skipping documents with only comments
 func (h *Handler) GetManifestsFromYaml(yamlFile string) ([]string, error) {
-	data, err := os.ReadFile(yamlFile)
-	if err != nil {
-		return nil, err
-	}
-
-	manifests := make([]string, 0)
-	// matches lines that start with "---" and may have trailing spaces after and have a newline
-	re := regexp.MustCompile(`(?m)^---\s*\n`)
-	yamlParts := re.Split(string(data), -1)
-	for _, part := range yamlParts {
-		if part == "" || part == "\n" {
-			continue
-		}
-		manifests = append(manifests, part)
-	}
-	if len(manifests) == 0 {
-		return nil, nil
-	}
-
-	return manifests, nil
+	return ymlutils.SplitDocumentsFromFile(fsutils.OrOS(h.FS), yamlFile)
 }
 
 func (h *Handler) CreateObjectsFromManifests(manifests []string) ([]runtime.Object, error) {
 	objects := make([]runtime.Object, 0, len(manifests))
-	for _, manifest := range manifests {
+	for i, manifest := range manifests {
 		obj, err := h.CreateObjectFromManifest(manifest)
 		if err != nil {
-			return nil, fmt.Errorf("while creating object from manifest: %w", err)
+			return nil, fmt.Errorf("while creating object from manifest %d: %w", i, err)
 		}
 		objects = append(objects, obj)
 	}
@@ -2,8 +2,12 @@ package manifest
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -88,3 +93,186 @@ func TestHandler_SuccessPath(t *testing.T) {
 		assert.Contains(t, objs, configMapAsRuntimeObject)
 	})
 }
+
+// legacySplitManifests is the regex-based splitting which GetManifestsFromYaml used before the YAML decoder
+func legacySplitManifests(data string) []string {
+	manifests := make([]string, 0)
+	for _, part := range regexp.MustCompile(`(?m)^---\s*\n`).Split(data, -1) {
+		if part == "" || part == "\n" {
+			continue
+		}
+		manifests = append(manifests, part)
+	}
+	return manifests
+}
+
+// unmarshalManifests returns the manifests as objects, skipping empty documents
+func unmarshalManifests(manifests []string) ([]interface{}, error) {
+	objects := make([]interface{}, 0, len(manifests))
+	for _, manifest := range manifests {
+		var obj interface{}
+		if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil {
+			return nil, err
+		}
+		if obj != nil {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func moduleResourcesFiles(t testing.TB) []string {
+	files := make([]string, 0)
+	for _, dir := range []string{resourcesDir, "../../module-resources/apply", "../../module-resources/delete", "../../module-resources/excluded"} {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.y*ml"))
+		require.NoError(t, err)
+		files = append(files, matches...)
+	}
+	require.NotEmpty(t, files)
+	return files
+}
+
+func TestHandler_GetManifestsFromYamlParity(t *testing.T) {
+	handler := Handler{Scheme: clientgoscheme.Scheme}
+
+	for _, file := range moduleResourcesFiles(t) {
+		t.Run(file, func(t *testing.T) {
+			// given
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			expected, err := unmarshalManifests(legacySplitManifests(string(data)))
+			require.NoError(t, err)
+
+			// when
+			manifests, err := handler.GetManifestsFromYaml(file)
+
+			// then
+			require.NoError(t, err)
+			actual, err := unmarshalManifests(manifests)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestHandler_GetManifestsFromYaml(t *testing.T) {
+	// given
+	handler := Handler{Scheme: clientgoscheme.Scheme}
+	dir := t.TempDir()
+	writeYaml := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	t.Run("should split all valid YAML documents", func(t *testing.T) {
+		// given
+		path := writeYaml("valid.yaml", `--- # first document
+  # indented comment
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: first
+data:
+  script: |
+    ---
+    kind: Secret
+---
+---   
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: second}
+...
+`)
+
+		// when
+		manifests, err := handler.GetManifestsFromYaml(path)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, manifests, 2)
+		objs, err := handler.CreateObjectsFromManifests(manifests)
+		require.NoError(t, err)
+		first, second := objs[0].(*corev1.ConfigMap), objs[1].(*corev1.ConfigMap)
+		assert.Equal(t, "first", first.Name)
+		assert.Equal(t, "---\nkind: Secret\n", first.Data["script"])
+		assert.Equal(t, "second", second.Name)
+	})
+
+	t.Run("should report the file and the document index of invalid YAML", func(t *testing.T) {
+		// given
+		path := writeYaml("invalid.yaml", "kind: ConfigMap\n---\n---\nkind: [\n")
+
+		// when
+		_, err := handler.GetManifestsFromYaml(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path)
+		assert.Contains(t, err.Error(), "document 2")
+	})
+}
+
+func TestHandler_WithFS(t *testing.T) {
+	// given
+	handler := (&Handler{Scheme: clientgoscheme.Scheme}).WithFS(fstest.MapFS{
+		"resources/apply/configmap.yml":  {Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: first\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: second\n")},
+		"resources/apply/secret.yaml":    {Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n")},
+		"resources/apply/README.md":      {Data: []byte("not a manifest")},
+		"resources/delete/to-delete.yml": {Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: deleted\n")},
+	})
+
+	t.Run("should collect objects from the directory in the filesystem", func(t *testing.T) {
+		// when
+		objs, err := handler.CollectObjectsFromDir("resources/apply")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, objs, 3)
+		assert.Equal(t, "first", objs[0].(*corev1.ConfigMap).Name)
+		assert.Equal(t, "second", objs[1].(*corev1.ConfigMap).Name)
+		assert.Equal(t, "secret", objs[2].(*corev1.Secret).Name)
+	})
+
+	t.Run("should return an error for a missing directory", func(t *testing.T) {
+		// when
+		_, err := handler.CollectObjectsFromDir("resources/missing")
+
+		// then
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+}
+
+func FuzzHandler_GetManifestsFromYaml(f *testing.F) {
+	for _, file := range moduleResourcesFiles(f) {
+		data, err := os.ReadFile(file)
+		require.NoError(f, err)
+		f.Add(string(data))
+	}
+	// inputs found by fuzzing
+	f.Add("!")
+	f.Add("#>\r>\r")
+	handler := Handler{Scheme: clientgoscheme.Scheme}
+	dir := f.TempDir()
+
+	f.Fuzz(func(t *testing.T, data string) {
+		path := filepath.Join(dir, "fuzz.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+		manifests, err := handler.GetManifestsFromYaml(path)
+		if err != nil {
+			return
+		}
+
+		// the legacy splitting is correct only for documents which it can parse and which have no content after separators
+		if regexp.MustCompile(`(?m)^(---[^\n]*\S|\.\.\.|%)`).MatchString(data) {
+			return
+		}
+		expected, err := unmarshalManifests(legacySplitManifests(data))
+		if err != nil {
+			return
+		}
+		actual, err := unmarshalManifests(manifests)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
@@ -0,0 +1,203 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"github.com/kyma-project/btp-manager/internal/ymlutils"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/ignore"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	helmHookAnnotation = "helm.sh/hook"
+	preDeleteHook      = "pre-delete"
+)
+
+var utf8bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Renderer renders the manifests of a Helm chart the same way as `helm template` does.
+// The lookup function isn't connected to the cluster, and resources with the pre-delete hook are skipped,
+// because they are excluded from the module resources as well.
+type Renderer struct {
+	// FS is the filesystem with the chart directory, the OS filesystem is used if it's nil
+	FS        fs.FS
+	ChartPath string
+	chart     *chart.Chart
+}
+
+func (r *Renderer) loadChart() (*chart.Chart, error) {
+	if r.chart == nil {
+		chrt, err := loadChartDir(fsutils.OrOS(r.FS), r.ChartPath)
+		if err != nil {
+			return nil, fmt.Errorf("while loading chart from %s: %w", r.ChartPath, err)
+		}
+		r.chart = chrt
+	}
+	return r.chart, nil
+}
+
+// loadChartDir loads the chart from the directory in the filesystem the same way as loader.LoadDir does from the OS filesystem,
+// files matching the .helmignore rules are skipped
+func loadChartDir(fsys fs.FS, dir string) (*chart.Chart, error) {
+	dir = path.Clean(dir)
+	rules := ignore.Empty()
+	if data, err := fs.ReadFile(fsys, path.Join(dir, ignore.HelmIgnore)); err == nil {
+		if rules, err = ignore.Parse(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	rules.AddDefaults()
+
+	files := make([]*loader.BufferedFile, 0)
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == dir {
+			return nil
+		}
+		n := name
+		if dir != "." {
+			n = strings.TrimPrefix(name, dir+"/")
+		}
+		fi, err := fs.Stat(fsys, name)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rules.Ignore(n, fi) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if rules.Ignore(n, fi) {
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return fmt.Errorf("cannot load irregular file %s", name)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("while reading %s: %w", n, err)
+		}
+		files = append(files, &loader.BufferedFile{Name: n, Data: bytes.TrimPrefix(data, utf8bom)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.LoadFiles(files)
+}
+
+// Values deep-merges the given values with the default values of the chart and validates the result against the values schema
+// of the chart. Charts without the values.schema.json file are validated against a schema derived from their default values.
+func (r *Renderer) Values(values map[string]interface{}) (map[string]interface{}, error) {
+	chrt, err := r.loadChart()
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("while merging chart values: %w", err)
+	}
+
+	schema := chrt.Schema
+	if len(schema) == 0 {
+		if schema, err = valuesSchema(chrt.Values); err != nil {
+			return nil, err
+		}
+	}
+	if err := chartutil.ValidateAgainstSingleSchema(merged, schema); err != nil {
+		return nil, fmt.Errorf("chart values don't meet the schema: %w", err)
+	}
+
+	return merged, nil
+}
+
+// Render templates the chart with the given values, which are merged with the default values of the chart and validated.
+// The chart name is used as the release name, and the manifests are returned in the order of the template file names.
+func (r *Renderer) Render(namespace string, values map[string]interface{}) ([]string, error) {
+	chrt, err := r.loadChart()
+	if err != nil {
+		return nil, err
+	}
+	if values, err = r.Values(values); err != nil {
+		return nil, err
+	}
+
+	releaseOptions := chartutil.ReleaseOptions{
+		Name:      chrt.Name(),
+		Namespace: namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(chrt, values, releaseOptions, chartutil.DefaultCapabilities)
+	if err != nil {
+		return nil, fmt.Errorf("while preparing chart values: %w", err)
+	}
+
+	rendered, err := engine.Render(chrt, renderValues)
+	if err != nil {
+		return nil, fmt.Errorf("while rendering chart: %w", err)
+	}
+
+	templates := make([]string, 0, len(rendered))
+	for name := range rendered {
+		if isYamlFile(name) {
+			templates = append(templates, name)
+		}
+	}
+	sort.Strings(templates)
+
+	manifests := make([]string, 0)
+	for _, template := range templates {
+		templateManifests, err := ymlutils.SplitDocuments(path.Base(template), strings.NewReader(rendered[template]))
+		if err != nil {
+			return nil, fmt.Errorf("while splitting rendered manifests: %w", err)
+		}
+		for _, manifest := range templateManifests {
+			skip, err := r.skipManifest(manifest)
+			if err != nil {
+				return nil, fmt.Errorf("while reading manifest rendered from %s: %w", path.Base(template), err)
+			}
+			if !skip {
+				manifests = append(manifests, manifest)
+			}
+		}
+	}
+
+	return manifests, nil
+}
+
+// skipManifest returns true for documents without content and resources with the pre-delete hook
+func (r *Renderer) skipManifest(manifest string) (bool, error) {
+	var obj struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil {
+		return false, err
+	}
+	if obj.Kind == "" {
+		return true, nil
+	}
+	return strings.Contains(obj.Metadata.Annotations[helmHookAnnotation], preDeleteHook), nil
+}
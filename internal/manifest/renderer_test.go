@@ -0,0 +1,137 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	// given
+	chartPath := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(chartPath, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(chartPath, name), []byte(content), 0o644))
+	}
+	writeFile("Chart.yaml", "apiVersion: v2\nname: test-chart\nversion: v0.0.1\n")
+	writeFile("values.yaml", "replicas: 1\nenabled: true\nnamespaces: []\n")
+	writeFile("templates/configmap.yml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+  namespace: {{ .Release.Namespace }}
+data:
+  REPLICAS: {{ .Values.replicas | quote }}
+  NAMESPACES: {{ join "," .Values.namespaces | quote }}
+---
+{{- if not .Values.enabled }}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: disabled
+{{- end }}
+`)
+	writeFile("templates/job.yml", `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: cleanup
+  annotations:
+    "helm.sh/hook": pre-delete
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}-secret
+`)
+	writeFile("templates/_helpers.tpl", `{{- define "test.name" -}}test{{- end -}}`)
+	writeFile("templates/NOTES.txt", "notes")
+	renderer := Renderer{ChartPath: chartPath}
+
+	t.Run("should render manifests with the given values", func(t *testing.T) {
+		// when
+		manifests, err := renderer.Render("kyma-system", map[string]interface{}{
+			"replicas":   2,
+			"namespaces": []interface{}{"ns1", "ns2"},
+		})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, manifests, 2)
+		assert.Contains(t, manifests[0], "name: test-chart-config")
+		assert.Contains(t, manifests[0], "namespace: kyma-system")
+		assert.Contains(t, manifests[0], `REPLICAS: "2"`)
+		assert.Contains(t, manifests[0], `NAMESPACES: "ns1,ns2"`)
+		assert.Contains(t, manifests[1], "name: test-chart-secret")
+	})
+
+	t.Run("should use the default values", func(t *testing.T) {
+		// when
+		manifests, err := renderer.Render("kyma-system", nil)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, manifests, 2)
+		assert.Contains(t, manifests[0], `REPLICAS: "1"`)
+	})
+
+	t.Run("should render conditional manifests", func(t *testing.T) {
+		// when
+		manifests, err := renderer.Render("kyma-system", map[string]interface{}{"enabled": false})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, manifests, 3)
+		assert.Contains(t, manifests[1], "name: disabled")
+	})
+
+	t.Run("should reject values which don't meet the schema derived from the default values", func(t *testing.T) {
+		// when
+		_, err := renderer.Values(map[string]interface{}{"enabled": "yes", "namespaces": "ns1", "replicas": "2", "extra": 1})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "enabled")
+		assert.Contains(t, err.Error(), "namespaces")
+		assert.NotContains(t, err.Error(), "replicas")
+		assert.NotContains(t, err.Error(), "extra")
+	})
+
+	t.Run("should render the chart from the filesystem and skip ignored files", func(t *testing.T) {
+		// given
+		renderer := Renderer{
+			FS: fstest.MapFS{
+				"module/chart/Chart.yaml":                {Data: []byte("apiVersion: v2\nname: test-chart\nversion: v0.0.1\n")},
+				"module/chart/values.yaml":               {Data: []byte("name: fs\n")},
+				"module/chart/.helmignore":               {Data: []byte("templates/ignored.yml\n")},
+				"module/chart/templates/configmap.yml":   {Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Values.name }}\n")},
+				"module/chart/templates/ignored.yml":     {Data: []byte("{{ fail \"ignored\" }}\n")},
+				"module/chart/templates/sub/secret.yaml": {Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n")},
+			},
+			ChartPath: "module/chart",
+		}
+
+		// when
+		manifests, err := renderer.Render("kyma-system", nil)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, manifests, 2)
+		assert.Contains(t, manifests[0], "name: fs")
+		assert.Contains(t, manifests[1], "name: secret")
+	})
+
+	t.Run("should return an error for a missing chart", func(t *testing.T) {
+		// given
+		renderer := Renderer{ChartPath: filepath.Join(chartPath, "missing")}
+
+		// when
+		_, err := renderer.Render("kyma-system", nil)
+
+		// then
+		assert.Error(t, err)
+	})
+}
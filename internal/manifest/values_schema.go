@@ -0,0 +1,47 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// valuesSchema derives a JSON schema from the default values of a chart. Maps and lists keep their type, booleans must stay booleans,
+// and other scalars accept strings and numbers, because quantities like CPU limits are written in both forms.
+// Keys which are not in the default values and keys with null defaults aren't constrained.
+func valuesSchema(defaults map[string]interface{}) ([]byte, error) {
+	schema := objectSchema(defaults)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("while creating values schema: %w", err)
+	}
+	return data, nil
+}
+
+func objectSchema(values map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if property := valueSchema(value); property != nil {
+			properties[key] = property
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+func valueSchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return objectSchema(v)
+	case []interface{}:
+		return map[string]interface{}{"type": "array"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case string, float64, int, int64:
+		return map[string]interface{}{"type": []string{"string", "number"}}
+	default:
+		return nil
+	}
+}
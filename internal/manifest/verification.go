@@ -0,0 +1,181 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ChecksumsFile is the name of the file with checksums of the manifests in a directory
+const ChecksumsFile = "checksums.json"
+
+// Checksums are the SHA-256 checksums of the manifests in a directory, with the digest of all checksums, which is
+// optionally signed with an ed25519 key
+type Checksums struct {
+	// Files maps the names of the manifest files to their hex-encoded SHA-256 checksums
+	Files map[string]string `json:"files"`
+	// Digest is the hex-encoded SHA-256 checksum of the sorted lines "<checksum>  <name>" of the files, like in the sha256sum output
+	Digest string `json:"digest"`
+	// Signature is the base64-encoded ed25519 signature of the digest
+	Signature string `json:"signature,omitempty"`
+}
+
+// VerificationError is an error of manifests which don't match their checksums or the signature
+type VerificationError struct {
+	Dir      string
+	Problems []string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification of manifests in %s directory failed: %s", e.Dir, strings.Join(e.Problems, ", "))
+}
+
+// ComputeChecksums returns checksums of the manifests in the directory, signed if the key is set
+func ComputeChecksums(fsys fs.FS, dir string, key ed25519.PrivateKey) (*Checksums, error) {
+	files, err := manifestFileNames(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := &Checksums{Files: make(map[string]string, len(files))}
+	for _, file := range files {
+		checksum, err := fileChecksum(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		checksums.Files[file] = checksum
+	}
+	checksums.Digest = checksums.computeDigest()
+	if key != nil {
+		checksums.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(checksums.Digest)))
+	}
+
+	return checksums, nil
+}
+
+// verifyDir verifies the manifests in the directory against the checksums file. Without the public key, the checksums file
+// is optional, otherwise it must exist and be signed with the private key of the public key.
+func (h *Handler) verifyDir(fsys fs.FS, dir string) error {
+	data, err := fs.ReadFile(fsys, path.Join(dir, ChecksumsFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		if h.PublicKey == nil {
+			return nil
+		}
+		return &VerificationError{Dir: dir, Problems: []string{fmt.Sprintf("%s file is missing", ChecksumsFile)}}
+	}
+	if err != nil {
+		return fmt.Errorf("while reading %s file: %w", ChecksumsFile, err)
+	}
+	checksums := &Checksums{}
+	if err := json.Unmarshal(data, checksums); err != nil {
+		return &VerificationError{Dir: dir, Problems: []string{fmt.Sprintf("invalid %s file: %s", ChecksumsFile, err)}}
+	}
+
+	files, err := manifestFileNames(fsys, dir)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, file := range files {
+		expected, found := checksums.Files[file]
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s has no checksum", file))
+			continue
+		}
+		checksum, err := fileChecksum(fsys, path.Join(dir, file))
+		if err != nil {
+			return err
+		}
+		if checksum != expected {
+			problems = append(problems, fmt.Sprintf("%s checksum mismatch", file))
+		}
+	}
+	for _, file := range slices.Sorted(maps.Keys(checksums.Files)) {
+		if !slices.Contains(files, file) {
+			problems = append(problems, fmt.Sprintf("%s is missing", file))
+		}
+	}
+	if digest := checksums.computeDigest(); digest != checksums.Digest {
+		problems = append(problems, "digest mismatch")
+	}
+	if h.PublicKey != nil {
+		if err := checksums.verifySignature(h.PublicKey); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return &VerificationError{Dir: dir, Problems: problems}
+	}
+	return nil
+}
+
+func (c *Checksums) computeDigest() string {
+	var lines strings.Builder
+	for _, file := range slices.Sorted(maps.Keys(c.Files)) {
+		lines.WriteString(fmt.Sprintf("%s  %s\n", c.Files[file], file))
+	}
+	digest := sha256.Sum256([]byte(lines.String()))
+	return hex.EncodeToString(digest[:])
+}
+
+func (c *Checksums) verifySignature(key ed25519.PublicKey) error {
+	if c.Signature == "" {
+		return errors.New("signature is missing")
+	}
+	signature, err := base64.StdEncoding.DecodeString(c.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+	if !ed25519.Verify(key, []byte(c.Digest), signature) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func manifestFileNames(fsys fs.FS, dir string) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if isYamlFile(entry.Name()) {
+			files = append(files, entry.Name())
+		}
+	}
+	return files, nil
+}
+
+func fileChecksum(fsys fs.FS, file string) (string, error) {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return "", err
+	}
+	checksum := sha256.Sum256(data)
+	return hex.EncodeToString(checksum[:]), nil
+}
+
+// ParsePublicKey parses the base64-encoded ed25519 public key, the empty key is nil
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	if key == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(data))
+	}
+	return ed25519.PublicKey(data), nil
+}
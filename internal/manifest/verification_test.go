@@ -0,0 +1,128 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestHandler_CollectObjectsFromDirVerification(t *testing.T) {
+	// given
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	otherPublicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	newFS := func() fstest.MapFS {
+		return fstest.MapFS{
+			"apply/configmap.yml": {Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")},
+			"apply/secret.yaml":   {Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret\n")},
+		}
+	}
+	writeChecksums := func(fsys fstest.MapFS, key ed25519.PrivateKey) {
+		checksums, err := ComputeChecksums(fsys, "apply", key)
+		require.NoError(t, err)
+		data, err := json.Marshal(checksums)
+		require.NoError(t, err)
+		fsys["apply/"+ChecksumsFile] = &fstest.MapFile{Data: data}
+	}
+	collect := func(fsys fstest.MapFS, key ed25519.PublicKey) error {
+		handler := (&Handler{Scheme: clientgoscheme.Scheme}).WithFS(fsys)
+		handler.PublicKey = key
+		_, err := handler.CollectObjectsFromDir("apply")
+		return err
+	}
+	verificationProblems := func(err error) []string {
+		var verificationErr *VerificationError
+		require.True(t, errors.As(err, &verificationErr))
+		return verificationErr.Problems
+	}
+
+	t.Run("should collect objects without the checksums file and the public key", func(t *testing.T) {
+		// when
+		err := collect(newFS(), nil)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should collect objects matching the signed checksums", func(t *testing.T) {
+		// given
+		fsys := newFS()
+		writeChecksums(fsys, privateKey)
+
+		// when
+		err := collect(fsys, publicKey)
+
+		// then
+		assert.NoError(t, err)
+	})
+
+	t.Run("should report modified, added, and removed manifests", func(t *testing.T) {
+		// given
+		fsys := newFS()
+		writeChecksums(fsys, nil)
+		fsys["apply/secret.yaml"].Data = []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: tampered\n")
+		fsys["apply/added.yaml"] = &fstest.MapFile{Data: []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: added\n")}
+		delete(fsys, "apply/configmap.yml")
+
+		// when
+		err := collect(fsys, nil)
+
+		// then
+		assert.Equal(t, []string{"added.yaml has no checksum", "secret.yaml checksum mismatch", "configmap.yml is missing"}, verificationProblems(err))
+		assert.Contains(t, err.Error(), "verification of manifests in apply directory failed")
+	})
+
+	t.Run("should report modified checksums", func(t *testing.T) {
+		// given
+		fsys := newFS()
+		checksums, err := ComputeChecksums(fsys, "apply", privateKey)
+		require.NoError(t, err)
+		fsys["apply/secret.yaml"].Data = []byte("tampered")
+		checksums.Files["secret.yaml"], err = fileChecksum(fsys, "apply/secret.yaml")
+		require.NoError(t, err)
+		data, err := json.Marshal(checksums)
+		require.NoError(t, err)
+		fsys["apply/"+ChecksumsFile] = &fstest.MapFile{Data: data}
+
+		// when
+		err = collect(fsys, publicKey)
+
+		// then
+		assert.Equal(t, []string{"digest mismatch"}, verificationProblems(err))
+	})
+
+	t.Run("should require checksums signed with the public key", func(t *testing.T) {
+		// given
+		unsigned, signed := newFS(), newFS()
+		writeChecksums(unsigned, nil)
+		writeChecksums(signed, privateKey)
+
+		// when
+		missingErr := collect(newFS(), publicKey)
+		unsignedErr := collect(unsigned, publicKey)
+		otherKeyErr := collect(signed, otherPublicKey)
+
+		// then
+		assert.Equal(t, []string{"checksums.json file is missing"}, verificationProblems(missingErr))
+		assert.Equal(t, []string{"signature is missing"}, verificationProblems(unsignedErr))
+		assert.Equal(t, []string{"signature mismatch"}, verificationProblems(otherKeyErr))
+	})
+}
+
+func TestParsePublicKey(t *testing.T) {
+	// when
+	empty, emptyErr := ParsePublicKey("")
+	_, invalidErr := ParsePublicKey("c2hvcnQ=")
+
+	// then
+	assert.NoError(t, emptyErr)
+	assert.Nil(t, empty)
+	assert.ErrorContains(t, invalidErr, "expected 32 bytes, got 5")
+}
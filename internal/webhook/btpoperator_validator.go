@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const BtpOperatorValidationPath = "/validate-operator-kyma-project-io-v1alpha1-btpoperator"
+
+// BtpOperatorValidator rejects BtpOperator CRs that would not be reconciled as the user expects
+type BtpOperatorValidator struct {
+	client.Reader
+}
+
+var _ admission.CustomValidator = &BtpOperatorValidator{}
+
+func NewBtpOperatorValidator(reader client.Reader) *BtpOperatorValidator {
+	return &BtpOperatorValidator{Reader: reader}
+}
+
+// SetupWithManager registers the validating webhook in the webhook server of the Manager.
+func (v *BtpOperatorValidator) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(BtpOperatorValidationPath, admission.WithCustomValidator(mgr.GetScheme(), &v1alpha1.BtpOperator{}, v))
+	return nil
+}
+
+func (v *BtpOperatorValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	logger := log.FromContext(ctx)
+	cr, ok := obj.(*v1alpha1.BtpOperator)
+	if !ok {
+		return nil, fmt.Errorf("expected BtpOperator but got %T", obj)
+	}
+	logger.Info("validating BtpOperator creation", "name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	if errs := v.validateBtpOperator(cr); len(errs) > 0 {
+		return nil, k8serrors.NewInvalid(v1alpha1.GroupVersion.WithKind("BtpOperator").GroupKind(), cr.GetName(), errs)
+	}
+
+	existingBtpOperators := &v1alpha1.BtpOperatorList{}
+	if err := v.List(ctx, existingBtpOperators); err != nil {
+		return nil, fmt.Errorf("while listing existing BtpOperators: %w", err)
+	}
+	var existing *v1alpha1.BtpOperator
+	for i := range existingBtpOperators.Items {
+		item := &existingBtpOperators.Items[i]
+		if item.GetNamespace() != cr.GetNamespace() || item.GetName() != cr.GetName() {
+			existing = item
+			break
+		}
+	}
+	if existing == nil {
+		return nil, nil
+	}
+	if cr.GetAnnotations()[v1alpha1.AllowAdditionalCRAnnotation] == "true" {
+		return admission.Warnings{fmt.Sprintf("the '%s' BtpOperator CR in '%s' namespace reconciles the module, the new CR stays in the Warning state until the existing one is deleted",
+			existing.GetName(), existing.GetNamespace())}, nil
+	}
+
+	return nil, k8serrors.NewForbidden(v1alpha1.GroupVersion.WithResource("btpoperators").GroupResource(), cr.GetName(),
+		fmt.Errorf("only one BtpOperator CR is allowed and the '%s' BtpOperator CR already exists in '%s' namespace. To create an additional CR anyway, for example during a migration, set the '%s: \"true\"' annotation",
+			existing.GetName(), existing.GetNamespace(), v1alpha1.AllowAdditionalCRAnnotation))
+}
+
+func (v *BtpOperatorValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	logger := log.FromContext(ctx)
+	oldCr, ok := oldObj.(*v1alpha1.BtpOperator)
+	if !ok {
+		return nil, fmt.Errorf("expected BtpOperator but got %T", oldObj)
+	}
+	newCr, ok := newObj.(*v1alpha1.BtpOperator)
+	if !ok {
+		return nil, fmt.Errorf("expected BtpOperator but got %T", newObj)
+	}
+	logger.Info("validating BtpOperator update", "name", newCr.GetName(), "namespace", newCr.GetNamespace())
+
+	if v.shouldValidateUpdatedFields(ctx, oldCr, newCr) {
+		if errs := v.validateBtpOperator(newCr); len(errs) > 0 {
+			return nil, k8serrors.NewInvalid(v1alpha1.GroupVersion.WithKind("BtpOperator").GroupKind(), newCr.GetName(), errs)
+		}
+	}
+
+	if v.isFinalizerRemovedDuringDeprovisioning(oldCr, newCr) && !v.isRequestedByManager(ctx) {
+		return nil, k8serrors.NewForbidden(v1alpha1.GroupVersion.WithResource("btpoperators").GroupResource(), newCr.GetName(),
+			fmt.Errorf("the '%s' finalizer cannot be removed while the module is being deprovisioned, wait until btp-manager removes it", v1alpha1.Finalizer))
+	}
+
+	return nil, nil
+}
+
+func (v *BtpOperatorValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *BtpOperatorValidator) validateBtpOperator(cr *v1alpha1.BtpOperator) field.ErrorList {
+	errs := field.ErrorList{}
+	errs = append(errs, v.validateLabels(cr)...)
+	errs = append(errs, v.validateSpec(cr)...)
+	return errs
+}
+
+func (v *BtpOperatorValidator) validateLabels(cr *v1alpha1.BtpOperator) field.ErrorList {
+	errs := field.ErrorList{}
+	labelsPath := field.NewPath("metadata", "labels")
+	if value, exists := cr.GetLabels()[v1alpha1.ForceDeleteLabelKey]; exists && value != "true" && value != "false" {
+		errs = append(errs, field.NotSupported(labelsPath.Key(v1alpha1.ForceDeleteLabelKey), value, []string{"true", "false"}))
+	}
+	return errs
+}
+
+// validateSpec is the place for cross-field rules the CRD schema cannot express.
+func (v *BtpOperatorValidator) validateSpec(cr *v1alpha1.BtpOperator) field.ErrorList {
+	errs := field.ErrorList{}
+	if cr.Spec.DeletionPolicy == v1alpha1.DeletionPolicyOrphan && cr.GetLabels()[v1alpha1.ForceDeleteLabelKey] == "true" {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "deletionPolicy"), cr.Spec.DeletionPolicy,
+			fmt.Sprintf("the %s deletion policy conflicts with the '%s: \"true\"' label, remove the label or use the %s deletion policy",
+				v1alpha1.DeletionPolicyOrphan, v1alpha1.ForceDeleteLabelKey, v1alpha1.DeletionPolicyDelete)))
+	}
+	return errs
+}
+
+// shouldValidateUpdatedFields returns false for updates, which don't change the validated fields, of CRs being deleted, and
+// of btp-manager, so that CRs created before the webhook existed can still be reconciled and finalized
+func (v *BtpOperatorValidator) shouldValidateUpdatedFields(ctx context.Context, oldCr, newCr *v1alpha1.BtpOperator) bool {
+	if !newCr.GetDeletionTimestamp().IsZero() || v.isRequestedByManager(ctx) {
+		return false
+	}
+	return oldCr.GetLabels()[v1alpha1.ForceDeleteLabelKey] != newCr.GetLabels()[v1alpha1.ForceDeleteLabelKey] ||
+		oldCr.Spec.DeletionPolicy != newCr.Spec.DeletionPolicy
+}
+
+func (v *BtpOperatorValidator) isFinalizerRemovedDuringDeprovisioning(oldCr, newCr *v1alpha1.BtpOperator) bool {
+	if oldCr.GetDeletionTimestamp().IsZero() || oldCr.Status.State != v1alpha1.StateDeleting {
+		return false
+	}
+	return ctrlutil.ContainsFinalizer(oldCr, v1alpha1.Finalizer) && !ctrlutil.ContainsFinalizer(newCr, v1alpha1.Finalizer)
+}
+
+func (v *BtpOperatorValidator) isRequestedByManager(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	return req.UserInfo.Username == ManagerServiceAccount
+}
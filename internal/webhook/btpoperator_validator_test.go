@@ -0,0 +1,257 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestBtpOperatorValidator_ValidateCreate(t *testing.T) {
+	// suite setup
+	scheme := newScheme(t)
+
+	t.Run("should allow the first BtpOperator", func(t *testing.T) {
+		// given
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+		// when
+		warnings, err := validator.ValidateCreate(context.Background(), newBtpOperator("btpoperator", "kyma-system"))
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("should reject a second BtpOperator", func(t *testing.T) {
+		// given
+		existing := newBtpOperator("btpoperator", "kyma-system")
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build())
+
+		// when
+		_, err := validator.ValidateCreate(context.Background(), newBtpOperator("second", "default"))
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsForbidden(err))
+		assert.Contains(t, err.Error(), v1alpha1.AllowAdditionalCRAnnotation)
+	})
+
+	t.Run("should allow a second BtpOperator with the override annotation", func(t *testing.T) {
+		// given
+		existing := newBtpOperator("btpoperator", "kyma-system")
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build())
+		cr := newBtpOperator("second", "default")
+		cr.SetAnnotations(map[string]string{v1alpha1.AllowAdditionalCRAnnotation: "true"})
+
+		// when
+		warnings, err := validator.ValidateCreate(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, warnings, 1)
+	})
+
+	t.Run("should reject an unsupported force-delete label value", func(t *testing.T) {
+		// given
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "yes"})
+
+		// when
+		_, err := validator.ValidateCreate(context.Background(), cr)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsInvalid(err))
+	})
+
+	t.Run("should reject the Orphan deletion policy with the force-delete label", func(t *testing.T) {
+		// given
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "true"})
+		cr.Spec.DeletionPolicy = v1alpha1.DeletionPolicyOrphan
+
+		// when
+		_, err := validator.ValidateCreate(context.Background(), cr)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsInvalid(err))
+	})
+
+	t.Run("should allow the Delete deletion policy with the force-delete label", func(t *testing.T) {
+		// given
+		validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "true"})
+		cr.Spec.DeletionPolicy = v1alpha1.DeletionPolicyDelete
+
+		// when
+		_, err := validator.ValidateCreate(context.Background(), cr)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestBtpOperatorValidator_ValidateUpdate(t *testing.T) {
+	// suite setup
+	scheme := newScheme(t)
+	validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+	deprovisioningCr := func() *v1alpha1.BtpOperator {
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		now := metav1.Now()
+		cr.SetDeletionTimestamp(&now)
+		cr.SetFinalizers([]string{v1alpha1.Finalizer})
+		cr.Status.State = v1alpha1.StateDeleting
+		return cr
+	}
+
+	t.Run("should reject finalizer removal during deprovisioning", func(t *testing.T) {
+		// given
+		oldCr := deprovisioningCr()
+		newCr := oldCr.DeepCopy()
+		newCr.SetFinalizers(nil)
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext("kubernetes-admin"), oldCr, newCr)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsForbidden(err))
+	})
+
+	t.Run("should allow finalizer removal by btp-manager", func(t *testing.T) {
+		// given
+		oldCr := deprovisioningCr()
+		newCr := oldCr.DeepCopy()
+		newCr.SetFinalizers(nil)
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext(ManagerServiceAccount), oldCr, newCr)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should allow finalizer removal when the module is not being deprovisioned", func(t *testing.T) {
+		// given
+		oldCr := newBtpOperator("btpoperator", "kyma-system")
+		oldCr.SetFinalizers([]string{v1alpha1.Finalizer})
+		oldCr.Status.State = v1alpha1.StateReady
+		newCr := oldCr.DeepCopy()
+		newCr.SetFinalizers(nil)
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext("kubernetes-admin"), oldCr, newCr)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestBtpOperatorValidator_ValidateUpdateFields(t *testing.T) {
+	// suite setup
+	scheme := newScheme(t)
+	validator := NewBtpOperatorValidator(fake.NewClientBuilder().WithScheme(scheme).Build())
+	invalidCr := func() *v1alpha1.BtpOperator {
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "true"})
+		cr.SetFinalizers([]string{v1alpha1.Finalizer})
+		cr.Spec.DeletionPolicy = v1alpha1.DeletionPolicyOrphan
+		return cr
+	}
+
+	t.Run("should reject an update which makes the CR invalid", func(t *testing.T) {
+		// given
+		oldCr := newBtpOperator("btpoperator", "kyma-system")
+		newCr := oldCr.DeepCopy()
+		newCr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "yes"})
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext("kubernetes-admin"), oldCr, newCr)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsInvalid(err))
+	})
+
+	t.Run("should allow an update of an invalid CR which doesn't change the validated fields", func(t *testing.T) {
+		// given
+		oldCr := invalidCr()
+		newCr := oldCr.DeepCopy()
+		newCr.SetAnnotations(map[string]string{"description": "created before the webhook"})
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext("kubernetes-admin"), oldCr, newCr)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should allow btp-manager to update an invalid CR", func(t *testing.T) {
+		// given
+		oldCr := invalidCr()
+		newCr := oldCr.DeepCopy()
+		newCr.Spec.DeletionPolicy = v1alpha1.DeletionPolicyOrphan
+		newCr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "yes"})
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext(ManagerServiceAccount), oldCr, newCr)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("should allow finalizer removal of an invalid CR being deleted", func(t *testing.T) {
+		// given
+		oldCr := invalidCr()
+		now := metav1.Now()
+		oldCr.SetDeletionTimestamp(&now)
+		newCr := oldCr.DeepCopy()
+		newCr.SetFinalizers(nil)
+		newCr.SetLabels(map[string]string{v1alpha1.ForceDeleteLabelKey: "yes"})
+
+		// when
+		_, err := validator.ValidateUpdate(requestContext("kubernetes-admin"), oldCr, newCr)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func newBtpOperator(name, namespace string) *v1alpha1.BtpOperator {
+	return &v1alpha1.BtpOperator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
+
+func requestContext(username string) context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: username},
+		},
+	})
+}
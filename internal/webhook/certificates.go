@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kyma-project/btp-manager/internal/certs"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Configuration options that can be overwritten by CLI parameters
+var (
+	Namespace                   = "kyma-system"
+	ServiceName                 = "btp-manager-webhook-service"
+	ValidatingWebhookConfigName = "btp-manager-validating-webhook-configuration"
+	CertSecretName              = "btp-manager-webhook-server-cert"
+	CertDir                     = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	ManagerServiceAccount       = "system:serviceaccount:kyma-system:btp-manager-controller-manager"
+	CaCertificateExpiration     = time.Hour * 87600 // 10 years
+	CertificateExpiration       = time.Hour * 8760  // 1 year
+	ExpirationBoundary          = time.Hour * -168  // 1 week
+	CertificateCheckInterval    = time.Hour
+)
+
+const (
+	caCertKey  = "ca.crt"
+	caKeyKey   = "ca.key"
+	tlsCertKey = "tls.crt"
+	tlsKeyKey  = "tls.key"
+)
+
+// CertificateProvisioner keeps the serving certificate of the btp-manager webhook server and the CA bundle
+// in the btp-manager webhook configuration in sync
+type CertificateProvisioner struct {
+	reader client.Reader
+	writer client.Writer
+}
+
+var _ manager.LeaderElectionRunnable = &CertificateProvisioner{}
+
+// NewCertificateProvisioner takes a separate reader because provisioning runs before the Manager cache is started.
+func NewCertificateProvisioner(reader client.Reader, writer client.Writer) *CertificateProvisioner {
+	return &CertificateProvisioner{
+		reader: reader,
+		writer: writer,
+	}
+}
+
+// Provision makes sure the certificates Secret holds a valid CA and serving certificate,
+// writes the serving certificate to CertDir and injects the CA into the webhook configuration.
+func (p *CertificateProvisioner) Provision(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	secret, err := p.ensureCertificatesSecret(ctx)
+	if err != nil {
+		return fmt.Errorf("while ensuring webhook certificates Secret: %w", err)
+	}
+
+	logger.Info("writing webhook serving certificate", "dir", CertDir)
+	if err := p.writeServingCertificate(secret); err != nil {
+		return fmt.Errorf("while writing webhook serving certificate: %w", err)
+	}
+
+	if err := p.injectCaBundle(ctx, secret.Data[caCertKey]); err != nil {
+		return fmt.Errorf("while injecting CA bundle into %s: %w", ValidatingWebhookConfigName, err)
+	}
+
+	return nil
+}
+
+// SetupWithManager adds the provisioner to the Manager, which starts it after the certificates are provisioned at startup.
+func (p *CertificateProvisioner) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(p)
+}
+
+// Start provisions the certificates every CertificateCheckInterval, so that they are renewed before they expire.
+// The webhook server reloads the serving certificate when its files in CertDir change.
+func (p *CertificateProvisioner) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	ticker := time.NewTicker(CertificateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := p.Provision(ctx); err != nil {
+				logger.Error(err, "while provisioning webhook certificates")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, because every replica serves the webhooks with the serving certificate in its own CertDir.
+func (p *CertificateProvisioner) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *CertificateProvisioner) ensureCertificatesSecret(ctx context.Context) (*corev1.Secret, error) {
+	logger := log.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	err := p.reader.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	secretExists := err == nil
+
+	if secretExists && p.certificatesAreValid(secret.Data) {
+		logger.Info("webhook certificates are valid")
+		return secret, nil
+	}
+
+	logger.Info("generating webhook certificates")
+	data, err := p.generateCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	if !secretExists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      CertSecretName,
+				Namespace: Namespace,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		return secret, p.writer.Create(ctx, secret)
+	}
+	secret.Data = data
+	return secret, p.writer.Update(ctx, secret)
+}
+
+func (p *CertificateProvisioner) certificatesAreValid(data map[string][]byte) bool {
+	for _, key := range []string{caCertKey, caKeyKey, tlsCertKey, tlsKeyKey} {
+		if len(data[key]) == 0 {
+			return false
+		}
+	}
+	signed, err := certs.VerifyIfLeafIsSignedByGivenCA(data[caCertKey], data[tlsCertKey])
+	if err != nil || !signed {
+		return false
+	}
+	for _, key := range []string{caCertKey, tlsCertKey} {
+		if p.expiresSoon(data[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *CertificateProvisioner) expiresSoon(certificate []byte) bool {
+	decoded, err := certs.TryDecodeCertificate(certificate)
+	if err != nil {
+		return true
+	}
+	parsed, err := x509.ParseCertificate(decoded.Bytes)
+	if err != nil {
+		return true
+	}
+	return time.Now().UTC().After(parsed.NotAfter.UTC().Add(ExpirationBoundary))
+}
+
+func (p *CertificateProvisioner) generateCertificates() (map[string][]byte, error) {
+	dnsNames := p.dnsNames()
+	caCertificate, caPrivateKey, err := certs.GenerateSelfSignedCertificateForDNSNames(time.Now().UTC().Add(CaCertificateExpiration), dnsNames)
+	if err != nil {
+		return nil, fmt.Errorf("while generating CA certificate: %w", err)
+	}
+	certificate, privateKey, err := certs.GenerateSignedCertificateForDNSNames(time.Now().UTC().Add(CertificateExpiration), caCertificate, caPrivateKey, dnsNames)
+	if err != nil {
+		return nil, fmt.Errorf("while generating serving certificate: %w", err)
+	}
+
+	return map[string][]byte{
+		caCertKey:  caCertificate,
+		caKeyKey:   caPrivateKey,
+		tlsCertKey: certificate,
+		tlsKeyKey:  privateKey,
+	}, nil
+}
+
+func (p *CertificateProvisioner) dnsNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", ServiceName, Namespace),
+		fmt.Sprintf("%s.%s", ServiceName, Namespace),
+	}
+}
+
+func (p *CertificateProvisioner) writeServingCertificate(secret *corev1.Secret) error {
+	if err := os.MkdirAll(CertDir, 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(CertDir, tlsCertKey), secret.Data[tlsCertKey], 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(CertDir, tlsKeyKey), secret.Data[tlsKeyKey], 0600)
+}
+
+func (p *CertificateProvisioner) injectCaBundle(ctx context.Context, caBundle []byte) error {
+	logger := log.FromContext(ctx)
+
+	webhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := p.reader.Get(ctx, client.ObjectKey{Name: ValidatingWebhookConfigName}, webhookConfig); err != nil {
+		if k8serrors.IsNotFound(err) {
+			logger.Info("webhook configuration not found, skipping CA bundle injection", "name", ValidatingWebhookConfigName)
+			return nil
+		}
+		return err
+	}
+
+	changed := false
+	for i := range webhookConfig.Webhooks {
+		if !bytes.Equal(webhookConfig.Webhooks[i].ClientConfig.CABundle, caBundle) {
+			webhookConfig.Webhooks[i].ClientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	logger.Info("injecting CA bundle into webhook configuration", "name", ValidatingWebhookConfigName)
+	return p.writer.Update(ctx, webhookConfig)
+}
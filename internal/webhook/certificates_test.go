@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/internal/certs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCertificateProvisioner_Provision(t *testing.T) {
+	// suite setup
+	scheme := newScheme(t)
+	rsaKeyBits := certs.RsaKeyBits()
+	certs.SetRsaKeyBits(2048)
+	defer certs.SetRsaKeyBits(rsaKeyBits)
+	certDir := CertDir
+	defer func() { CertDir = certDir }()
+
+	t.Run("should generate certificates and inject the CA bundle", func(t *testing.T) {
+		// given
+		CertDir = t.TempDir()
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newWebhookConfig()).Build()
+		provisioner := NewCertificateProvisioner(k8sClient, k8sClient)
+
+		// when
+		err := provisioner.Provision(context.Background())
+
+		// then
+		require.NoError(t, err)
+		secret := &corev1.Secret{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, secret))
+		signed, err := certs.VerifyIfLeafIsSignedByGivenCA(secret.Data[caCertKey], secret.Data[tlsCertKey])
+		require.NoError(t, err)
+		assert.True(t, signed)
+
+		servingCert, err := os.ReadFile(filepath.Join(CertDir, tlsCertKey))
+		require.NoError(t, err)
+		assert.Equal(t, secret.Data[tlsCertKey], servingCert)
+
+		webhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Name: ValidatingWebhookConfigName}, webhookConfig))
+		assert.Equal(t, secret.Data[caCertKey], webhookConfig.Webhooks[0].ClientConfig.CABundle)
+	})
+
+	t.Run("should reuse valid certificates", func(t *testing.T) {
+		// given
+		CertDir = t.TempDir()
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		provisioner := NewCertificateProvisioner(k8sClient, k8sClient)
+		require.NoError(t, provisioner.Provision(context.Background()))
+		before := &corev1.Secret{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, before))
+
+		// when
+		err := provisioner.Provision(context.Background())
+
+		// then
+		require.NoError(t, err)
+		after := &corev1.Secret{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, after))
+		assert.Equal(t, before.Data, after.Data)
+	})
+
+	t.Run("should regenerate incomplete certificates", func(t *testing.T) {
+		// given
+		CertDir = t.TempDir()
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: CertSecretName, Namespace: Namespace},
+			Data:       map[string][]byte{caCertKey: []byte("invalid")},
+		}
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+		provisioner := NewCertificateProvisioner(k8sClient, k8sClient)
+
+		// when
+		err := provisioner.Provision(context.Background())
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, secret))
+		assert.NotEmpty(t, secret.Data[tlsKeyKey])
+		signed, err := certs.VerifyIfLeafIsSignedByGivenCA(secret.Data[caCertKey], secret.Data[tlsCertKey])
+		require.NoError(t, err)
+		assert.True(t, signed)
+	})
+}
+
+func TestCertificateProvisioner_Start(t *testing.T) {
+	// given
+	scheme := newScheme(t)
+	rsaKeyBits := certs.RsaKeyBits()
+	certs.SetRsaKeyBits(2048)
+	defer certs.SetRsaKeyBits(rsaKeyBits)
+	defer func(certDir string, interval time.Duration) {
+		CertDir, CertificateCheckInterval = certDir, interval
+	}(CertDir, CertificateCheckInterval)
+	CertDir = t.TempDir()
+	CertificateCheckInterval = time.Millisecond * 10
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newWebhookConfig()).Build()
+	provisioner := NewCertificateProvisioner(k8sClient, k8sClient)
+	require.NoError(t, provisioner.Provision(context.Background()))
+	secret := &corev1.Secret{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, secret))
+	expired := secret.Data[tlsCertKey]
+	secret.Data[tlsCertKey] = []byte("expired")
+	require.NoError(t, k8sClient.Update(context.Background(), secret))
+
+	// when
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- provisioner.Start(ctx) }()
+
+	// then
+	assert.Eventually(t, func() bool {
+		servingCert, err := os.ReadFile(filepath.Join(CertDir, tlsCertKey))
+		if err != nil || bytes.Equal(servingCert, expired) {
+			return false
+		}
+		renewed := &corev1.Secret{}
+		if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: Namespace, Name: CertSecretName}, renewed); err != nil {
+			return false
+		}
+		return bytes.Equal(servingCert, renewed.Data[tlsCertKey])
+	}, time.Second*10, CertificateCheckInterval)
+	cancel()
+	assert.NoError(t, <-done)
+	assert.False(t, provisioner.NeedLeaderElection())
+}
+
+func newWebhookConfig() *admissionregistrationv1.ValidatingWebhookConfiguration {
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: ValidatingWebhookConfigName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{Name: "vbtpoperator.operator.kyma-project.io"},
+		},
+	}
+}
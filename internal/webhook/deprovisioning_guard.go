@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const DeprovisioningGuardPath = "/validate-services-cloud-sap-com-v1-deprovisioning-guard"
+
+// DeprovisioningGuard denies creation of ServiceInstances and ServiceBindings while the module is being deprovisioned,
+// so that new resources do not race the deletion of existing ones
+type DeprovisioningGuard struct {
+	client.Reader
+}
+
+var _ admission.Handler = &DeprovisioningGuard{}
+
+func NewDeprovisioningGuard(reader client.Reader) *DeprovisioningGuard {
+	return &DeprovisioningGuard{Reader: reader}
+}
+
+// SetupWithManager registers the validating webhook in the webhook server of the Manager.
+func (g *DeprovisioningGuard) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(DeprovisioningGuardPath, &admission.Webhook{Handler: g})
+	return nil
+}
+
+func (g *DeprovisioningGuard) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx)
+
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	cr, err := g.getDeprovisioningBtpOperator(ctx)
+	if err != nil {
+		logger.Error(err, "while checking if the module is being deprovisioned")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if cr == nil {
+		return admission.Allowed("")
+	}
+
+	logger.Info("denying creation during deprovisioning", "kind", req.Kind.Kind, "name", req.Name, "namespace", req.Namespace)
+	return admission.Denied(fmt.Sprintf("%s cannot be created because the SAP BTP Operator module is being deprovisioned. Check the status of the '%s' BtpOperator CR in '%s' namespace",
+		req.Kind.Kind, cr.GetName(), cr.GetNamespace()))
+}
+
+// getDeprovisioningBtpOperator returns the BtpOperator CR reconciling the module if it is in the Deleting state
+func (g *DeprovisioningGuard) getDeprovisioningBtpOperator(ctx context.Context) (*v1alpha1.BtpOperator, error) {
+	existingBtpOperators := &v1alpha1.BtpOperatorList{}
+	if err := g.List(ctx, existingBtpOperators); err != nil {
+		return nil, fmt.Errorf("while listing existing BtpOperators: %w", err)
+	}
+	if len(existingBtpOperators.Items) == 0 {
+		return nil, nil
+	}
+
+	oldestCr := existingBtpOperators.Items[0]
+	for _, item := range existingBtpOperators.Items {
+		itemCreationTimestamp := &item.CreationTimestamp
+		if !(oldestCr.CreationTimestamp.Before(itemCreationTimestamp)) {
+			oldestCr = item
+		}
+	}
+	if oldestCr.Status.State != v1alpha1.StateDeleting {
+		return nil, nil
+	}
+	return &oldestCr, nil
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/btp-manager/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestDeprovisioningGuard_Handle(t *testing.T) {
+	// suite setup
+	scheme := newScheme(t)
+	createRequest := newServiceInstanceRequest(admissionv1.Create)
+
+	t.Run("should allow creation when no BtpOperator exists", func(t *testing.T) {
+		// given
+		guard := NewDeprovisioningGuard(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+		// when
+		resp := guard.Handle(context.Background(), createRequest)
+
+		// then
+		assert.True(t, resp.Allowed)
+	})
+
+	t.Run("should allow creation when the module is ready", func(t *testing.T) {
+		// given
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.Status.State = v1alpha1.StateReady
+		guard := NewDeprovisioningGuard(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build())
+
+		// when
+		resp := guard.Handle(context.Background(), createRequest)
+
+		// then
+		assert.True(t, resp.Allowed)
+	})
+
+	t.Run("should deny creation when the module is being deprovisioned", func(t *testing.T) {
+		// given
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.Status.State = v1alpha1.StateDeleting
+		guard := NewDeprovisioningGuard(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build())
+
+		// when
+		resp := guard.Handle(context.Background(), createRequest)
+
+		// then
+		assert.False(t, resp.Allowed)
+		assert.Contains(t, resp.Result.Message, "ServiceInstance")
+		assert.Contains(t, resp.Result.Message, "'btpoperator' BtpOperator CR in 'kyma-system' namespace")
+	})
+
+	t.Run("should allow other operations when the module is being deprovisioned", func(t *testing.T) {
+		// given
+		cr := newBtpOperator("btpoperator", "kyma-system")
+		cr.Status.State = v1alpha1.StateDeleting
+		guard := NewDeprovisioningGuard(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build())
+
+		// when
+		resp := guard.Handle(context.Background(), newServiceInstanceRequest(admissionv1.Update))
+
+		// then
+		assert.True(t, resp.Allowed)
+	})
+
+	t.Run("should ignore the state of redundant BtpOperators", func(t *testing.T) {
+		// given
+		oldestCr := newBtpOperator("btpoperator", "kyma-system")
+		oldestCr.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+		oldestCr.Status.State = v1alpha1.StateReady
+		redundantCr := newBtpOperator("redundant", "default")
+		redundantCr.SetCreationTimestamp(metav1.Now())
+		redundantCr.Status.State = v1alpha1.StateDeleting
+		guard := NewDeprovisioningGuard(fake.NewClientBuilder().WithScheme(scheme).WithObjects([]client.Object{oldestCr, redundantCr}...).Build())
+
+		// when
+		resp := guard.Handle(context.Background(), createRequest)
+
+		// then
+		assert.True(t, resp.Allowed)
+	})
+}
+
+func newServiceInstanceRequest(operation admissionv1.Operation) admission.Request {
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Kind:      metav1.GroupVersionKind{Group: "services.cloud.sap.com", Version: "v1", Kind: "ServiceInstance"},
+			Name:      "my-service-instance",
+			Namespace: "default",
+		},
+	}
+}
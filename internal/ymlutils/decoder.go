@@ -0,0 +1,208 @@
+package ymlutils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	documentStartMarker = "---"
+	documentEndMarker   = "..."
+)
+
+// DocumentError is an error of a document in a multi-document YAML stream
+type DocumentError struct {
+	Name  string
+	Index int
+	Err   error
+}
+
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("%s: document %d: %s", e.Name, e.Index, e.Err)
+}
+
+func (e *DocumentError) Unwrap() error {
+	return e.Err
+}
+
+// Document is a document of a multi-document YAML stream with content other than comments and directives
+type Document struct {
+	// Index is the position of the document in the stream, documents without content are counted as well
+	Index int
+	// Content is the text of the document without the document markers
+	Content string
+	node    *yaml.Node
+}
+
+// Decode decodes the document into the value the same way as yaml.Unmarshal
+func (d *Document) Decode(v interface{}) error {
+	return d.node.Decode(v)
+}
+
+// Decoder reads documents of a multi-document YAML stream one by one. Documents are split on the document markers,
+// which are forbidden inside YAML content, so separators with comments and block scalars are handled, and the text of
+// documents is kept as is. Every document is parsed, and errors are returned as DocumentError with the name of the stream
+// and the index of the document.
+type Decoder struct {
+	name   string
+	reader *bufio.Reader
+	index  int
+	// carry is the content which follows the start marker of the next document in the same line
+	carry string
+	// absorbStart is true if the start marker doesn't end an empty document, which is at the beginning of the stream and after the end marker
+	absorbStart bool
+	eof         bool
+}
+
+func NewDecoder(name string, r io.Reader) *Decoder {
+	return &Decoder{name: name, reader: bufio.NewReader(r), absorbStart: true}
+}
+
+// Next returns the next document with content, or io.EOF at the end of the stream
+func (d *Decoder) Next() (*Document, error) {
+	for {
+		content, empty, err := d.readDocument()
+		if err != nil {
+			return nil, err
+		}
+		index := d.index
+		d.index++
+		if empty {
+			continue
+		}
+
+		node := &yaml.Node{}
+		if err := yaml.Unmarshal([]byte(content), node); err != nil {
+			return nil, &DocumentError{Name: d.name, Index: index, Err: err}
+		}
+		return &Document{Index: index, Content: content, node: node}, nil
+	}
+}
+
+// readDocument returns the text of the next document, in which every line ends with a new line, and true if the document
+// has only comments and directives, or io.EOF at the end of the stream
+func (d *Decoder) readDocument() (string, bool, error) {
+	if d.eof && d.carry == "" {
+		return "", false, io.EOF
+	}
+
+	var doc strings.Builder
+	doc.WriteString(d.carry)
+	d.carry = ""
+	// directives are allowed only before the start marker, which is a part of the document then
+	directives, content := false, doc.Len() > 0
+	for !d.eof {
+		line, err := d.reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			d.eof = true
+		} else if err != nil {
+			return "", false, &DocumentError{Name: d.name, Index: d.index, Err: err}
+		}
+		if line == "" {
+			break
+		}
+
+		if rest, ok := cutMarker(line, documentStartMarker); ok && !directives {
+			if doc.Len() == 0 && d.absorbStart {
+				d.absorbStart = false
+				doc.WriteString(rest)
+				content = rest != ""
+				continue
+			}
+			d.carry = rest
+			d.absorbStart = false
+			return doc.String(), !content, nil
+		}
+		if _, ok := cutMarker(line, documentEndMarker); ok {
+			if doc.Len() == 0 && d.absorbStart {
+				continue
+			}
+			d.absorbStart = true
+			return doc.String(), !content, nil
+		}
+
+		switch {
+		case directives && strings.HasPrefix(line, documentStartMarker):
+			directives = false
+			if rest, _ := cutMarker(line, documentStartMarker); rest != "" {
+				content = true
+			}
+		case !content && strings.HasPrefix(line, "%"):
+			directives = true
+		case hasContent(line):
+			content = true
+		}
+		d.absorbStart = false
+		doc.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			doc.WriteString("\n")
+		}
+	}
+	if doc.Len() == 0 {
+		return "", false, io.EOF
+	}
+	return doc.String(), !content, nil
+}
+
+// hasContent returns true if the line isn't blank or a comment, carriage returns are line breaks in YAML as well
+func hasContent(line string) bool {
+	for _, segment := range strings.Split(line, "\r") {
+		trimmed := strings.TrimSpace(segment)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// cutMarker returns the content after the document marker at the beginning of the line, without comments, and true if
+// the line starts with the marker
+func cutMarker(line, marker string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), marker)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" || strings.HasPrefix(rest, "#") {
+		return "", true
+	}
+	// the content is indented if it would be a marker at the beginning of the line
+	if _, ok := cutMarker(rest, documentStartMarker); ok {
+		rest = " " + rest
+	} else if _, ok := cutMarker(rest, documentEndMarker); ok {
+		rest = " " + rest
+	}
+	return rest + "\n", true
+}
+
+// SplitDocuments returns the documents with content of a multi-document YAML stream as separate manifests
+func SplitDocuments(name string, r io.Reader) ([]string, error) {
+	decoder := NewDecoder(name, r)
+	manifests := make([]string, 0)
+	for {
+		doc, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, doc.Content)
+	}
+}
+
+// SplitDocumentsFromFile returns the documents with content of a YAML file in the filesystem as separate manifests
+func SplitDocumentsFromFile(fsys fs.FS, path string) ([]string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return SplitDocuments(path, file)
+}
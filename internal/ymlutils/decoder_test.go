@@ -0,0 +1,153 @@
+package ymlutils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const moduleChartPath = "../../module-chart/chart"
+
+func TestSplitDocuments(t *testing.T) {
+	t.Run("should skip empty documents and keep their indexes", func(t *testing.T) {
+		// given
+		decoder := NewDecoder("test.yaml", strings.NewReader("---\n# comment only\n---\nkind: A\n---\n---\nkind: B\n"))
+
+		// when
+		first, err := decoder.Next()
+		require.NoError(t, err)
+		second, err := decoder.Next()
+		require.NoError(t, err)
+		_, err = decoder.Next()
+
+		// then
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, 1, first.Index)
+		assert.Equal(t, 3, second.Index)
+		assert.Equal(t, "kind: B\n", second.Content)
+	})
+
+	t.Run("should return DocumentError for invalid YAML", func(t *testing.T) {
+		// when
+		_, err := SplitDocuments("test.yaml", strings.NewReader("kind: A\n---\nkind: B\n  name: x\n"))
+
+		// then
+		var documentErr *DocumentError
+		require.True(t, errors.As(err, &documentErr))
+		assert.Equal(t, "test.yaml", documentErr.Name)
+		assert.Equal(t, 1, documentErr.Index)
+		assert.Contains(t, err.Error(), "test.yaml: document 1: ")
+	})
+}
+
+func TestExtractGvkFromYml(t *testing.T) {
+	// given
+	data := `# comment
+apiVersion: v1
+kind: ConfigMap
+data:
+  manifest: |
+    apiVersion: apps/v1
+    kind: Deployment
+--- # second
+  apiVersion: apps/v1
+  kind: Deployment
+---
+- kind: List
+`
+
+	// when
+	gvks, err := ExtractGvkFromYml(data)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionKind{
+		{Version: "v1", Kind: "ConfigMap"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}, gvks)
+}
+
+func TestGatherChartGvks(t *testing.T) {
+	// when
+	gvks, err := GatherChartGvks(moduleChartPath)
+
+	// then
+	require.NoError(t, err)
+	assert.Contains(t, gvks, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	assert.Contains(t, gvks, schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"})
+	assert.Contains(t, gvks, schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"})
+	assert.Contains(t, gvks, schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"})
+}
+
+func TestExtractStringValueFromYamlForGivenKey(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "Chart.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: v2
+# version: v0.0.0
+dependencies:
+  - name: dependency
+    version: 1.0.0
+name: chart
+version: "v0.1.2"
+`), 0o644))
+
+	t.Run("should return the value of a top-level key", func(t *testing.T) {
+		// when
+		version, err := ExtractStringValueFromYamlForGivenKey(path, "version")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "v0.1.2", version)
+	})
+
+	t.Run("should return an empty value of a missing key", func(t *testing.T) {
+		// when
+		value, err := ExtractStringValueFromYamlForGivenKey(path, "missing:")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, value)
+	})
+
+	t.Run("should return an error for a key which isn't a scalar", func(t *testing.T) {
+		// when
+		_, err := ExtractStringValueFromYamlForGivenKey(path, "dependencies")
+
+		// then
+		assert.Error(t, err)
+	})
+}
+
+func FuzzSplitDocuments(f *testing.F) {
+	templates, err := filepath.Glob(filepath.Join(moduleChartPath, "templates", "*"))
+	require.NoError(f, err)
+	for _, template := range templates {
+		data, err := os.ReadFile(template)
+		require.NoError(f, err)
+		f.Add(stripTemplateActions(string(data)))
+	}
+	// inputs found by fuzzing
+	f.Add("---\t---\t\t")
+	f.Add("#>\r>\r")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		manifests, err := SplitDocuments("fuzz.yaml", strings.NewReader(data))
+		if err != nil {
+			var documentErr *DocumentError
+			require.True(t, errors.As(err, &documentErr))
+			return
+		}
+
+		// splitting the joined manifests again gives the same manifests
+		again, err := SplitDocuments("joined.yaml", strings.NewReader(strings.Join(manifests, "---\n")))
+		require.NoError(t, err)
+		assert.Equal(t, manifests, again)
+	})
+}
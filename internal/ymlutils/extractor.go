@@ -1,19 +1,21 @@
 package ymlutils
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 
+	"github.com/kyma-project/btp-manager/internal/fsutils"
+	"gopkg.in/yaml.v3"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-const (
-	expectedLenAfterSplit = 2
-)
-
 func GatherChartGvks(chartPath string) ([]schema.GroupVersionKind, error) {
 	var allGvks []schema.GroupVersionKind
 	appendToSlice := func(gvk schema.GroupVersionKind) {
@@ -43,7 +45,7 @@ func GatherChartGvks(chartPath string) ([]schema.GroupVersionKind, error) {
 			return err
 		}
 
-		fileGvks, err := ExtractGvkFromYml(string(bytes))
+		fileGvks, err := extractGvks(path, stripTemplateActions(string(bytes)))
 		if err != nil {
 			return err
 		}
@@ -60,57 +62,114 @@ func GatherChartGvks(chartPath string) ([]schema.GroupVersionKind, error) {
 	return allGvks, nil
 }
 
+// ExtractGvkFromYml returns GVKs of documents with apiVersion and kind in a multi-document YAML
 func ExtractGvkFromYml(wholeFile string) ([]schema.GroupVersionKind, error) {
+	return extractGvks("YAML", wholeFile)
+}
+
+func extractGvks(name, data string) ([]schema.GroupVersionKind, error) {
 	var gvks []schema.GroupVersionKind
-	parts := strings.Split(wholeFile, "---\n")
-	for _, part := range parts {
-		if part == "" {
-			continue
+	decoder := NewDecoder(name, strings.NewReader(data))
+	for {
+		doc, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			return gvks, nil
 		}
-		var apiVersion, kind string
-		lines := strings.Split(part, "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "apiVersion:") {
-				apiVersion = strings.TrimSpace(strings.Split(line, ":")[1])
-			}
-			if strings.HasPrefix(line, "kind:") {
-				kind = strings.TrimSpace(strings.Split(line, ":")[1])
-			}
+		if err != nil {
+			return nil, err
 		}
-		if apiVersion != "" && kind != "" {
-			apiVersion, err := schema.ParseGroupVersion(apiVersion)
-			if err != nil {
-				return nil, err
-			}
-			gvks = append(gvks, apiVersion.WithKind(kind))
+
+		docGvks, err := documentGvks(doc)
+		if err != nil {
+			return nil, &DocumentError{Name: name, Index: doc.Index, Err: err}
+		}
+		gvks = append(gvks, docGvks...)
+	}
+}
+
+// documentGvks returns GVKs of all combinations of top-level apiVersion and kind keys of the document,
+// which are repeated in templates with alternative kinds
+func documentGvks(doc *Document) ([]schema.GroupVersionKind, error) {
+	if len(doc.node.Content) == 0 || doc.node.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	content := doc.node.Content[0]
+	var apiVersions, kinds []string
+	for i := 0; i+1 < len(content.Content); i += 2 {
+		key, value := content.Content[i], content.Content[i+1]
+		if value.Kind != yaml.ScalarNode || value.Value == "" {
+			continue
+		}
+		switch key.Value {
+		case "apiVersion":
+			apiVersions = append(apiVersions, value.Value)
+		case "kind":
+			kinds = append(kinds, value.Value)
 		}
 	}
 
+	var gvks []schema.GroupVersionKind
+	for _, apiVersion := range apiVersions {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, kind := range kinds {
+			gvks = append(gvks, gv.WithKind(kind))
+		}
+	}
 	return gvks, nil
 }
 
+// templateActionLine matches lines with only Helm template actions, for example conditions and loops
+var templateActionLine = regexp.MustCompile(`(?m)^[ \t]*(\{\{.*?\}\}[ \t]*)+$`)
+
+// templateAction matches Helm template actions inside lines
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// stripTemplateActions turns a Helm template into YAML by removing lines with only template actions and replacing other actions
+// with a placeholder, so both branches of conditions are kept
+func stripTemplateActions(template string) string {
+	template = templateActionLine.ReplaceAllString(template, "")
+	return templateAction.ReplaceAllString(template, "template")
+}
+
+// ExtractStringValueFromYamlForGivenKey returns the value of the top-level key of the first document in the YAML file,
+// or an empty string if the key doesn't exist
 func ExtractStringValueFromYamlForGivenKey(filePath string, key string) (string, error) {
-	if !strings.HasSuffix(key, ":") {
-		key = key + ":"
-	}
+	return ExtractStringValueFromFSYamlForGivenKey(fsutils.OSFS{}, filePath, key)
+}
+
+// ExtractStringValueFromFSYamlForGivenKey works like ExtractStringValueFromYamlForGivenKey with the YAML file in the filesystem
+func ExtractStringValueFromFSYamlForGivenKey(fsys fs.FS, filePath string, key string) (string, error) {
+	key = strings.TrimSuffix(key, ":")
 
-	file, err := os.ReadFile(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return "", err
 	}
-	lines := strings.Split(string(file), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, key) {
-			result := strings.Split(line, ":")
-			if len(result) != expectedLenAfterSplit {
-				return "", fmt.Errorf("line after split has incorrent number of elements: %d", len(result))
-			}
-			return strings.TrimSpace(result[1]), nil
-		}
+	defer file.Close()
+
+	doc, err := NewDecoder(filePath, file).Next()
+	if errors.Is(err, io.EOF) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
 	}
 
-	return "", nil
+	var values map[string]yaml.Node
+	if err := doc.Decode(&values); err != nil {
+		return "", &DocumentError{Name: filePath, Index: doc.Index, Err: err}
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", nil
+	}
+	if value.Kind != yaml.ScalarNode {
+		return "", &DocumentError{Name: filePath, Index: doc.Index, Err: fmt.Errorf("value of %s key is not a scalar", key)}
+	}
+	return value.Value, nil
 }
 
 func CopyManifestsFromYamlsIntoOneYaml(sourceManifestsDir, targetYaml string) error {
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"embed"
 	"flag"
 	"os"
 
@@ -33,10 +34,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/kyma-project/btp-manager/api/v1alpha1"
 	"github.com/kyma-project/btp-manager/controllers"
 	btpmanagermetrics "github.com/kyma-project/btp-manager/internal/metrics"
+	"github.com/kyma-project/btp-manager/internal/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -45,6 +48,11 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
+// moduleFS holds the module chart and module resources, which are used unless chart-path and resources-path are set
+//
+//go:embed all:module-chart all:module-resources
+var moduleFS embed.FS
+
 func init() {
 
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
@@ -57,6 +65,8 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var webhookPort int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -66,8 +76,14 @@ func main() {
 	flag.StringVar(&controllers.SecretName, "secret-name", controllers.SecretName, "Secret name with input values for sap-btp-operator chart templating.")
 	flag.StringVar(&controllers.ConfigName, "config-name", controllers.ConfigName, "ConfigMap name with configuration knobs for the btp-manager internals.")
 	flag.StringVar(&controllers.DeploymentName, "deployment-name", controllers.DeploymentName, "Name of the deployment of sap-btp-operator for deprovisioning.")
-	flag.StringVar(&controllers.ChartPath, "chart-path", controllers.ChartPath, "Path to the root directory inside the chart.")
-	flag.StringVar(&controllers.ResourcesPath, "resources-path", controllers.ResourcesPath, "Path to the directory with module resources to apply/delete.")
+	flag.StringVar(&controllers.ChartPath, "chart-path", controllers.ChartPath, "Path to the root directory inside the chart. The chart embedded into the binary is used if it's empty.")
+	flag.StringVar(&controllers.ResourcesPath, "resources-path", controllers.ResourcesPath, "Path to the directory with module resources to apply/delete. The module resources embedded into the binary are used if it's empty.")
+	flag.BoolVar(&controllers.RenderChart, "render-chart", controllers.RenderChart, "Render module resources to apply from the chart at reconcile time instead of using the pre-rendered ones.")
+	flag.StringVar(&controllers.ChartValuesConfigName, "chart-values-config-name", controllers.ChartValuesConfigName, "Name of the ConfigMap with chart value overrides. If the ConfigMap exists, module resources are rendered from the chart.")
+	flag.StringVar(&controllers.ManifestsPublicKey, "manifests-public-key", controllers.ManifestsPublicKey, "Base64-encoded ed25519 public key, which the checksums of module resources must be signed with. If it's empty, module resources are verified only against the checksums files present.")
+	flag.StringVar(&controllers.ImageRegistryMirrors, "image-registry-mirrors", controllers.ImageRegistryMirrors, "Comma-separated <prefix>=<mirror prefix> pairs to rewrite images of module resources with.")
+	flag.StringVar(&controllers.ImageDigests, "image-digests", controllers.ImageDigests, "Comma-separated <repository>=<sha256 digest> pairs to pin images of module resources to.")
+	flag.StringVar(&controllers.ImagePullSecrets, "image-pull-secrets", controllers.ImagePullSecrets, "Comma-separated names of Secrets to add as image pull Secrets to pod specs of module resources.")
 	flag.DurationVar(&controllers.ProcessingStateRequeueInterval, "processing-state-requeue-interval", controllers.ProcessingStateRequeueInterval, `Requeue interval for state "processing".`)
 	flag.DurationVar(&controllers.ReadyStateRequeueInterval, "ready-state-requeue-interval", controllers.ReadyStateRequeueInterval, `Requeue interval for state "ready".`)
 	flag.DurationVar(&controllers.ReadyTimeout, "ready-timeout", controllers.ReadyTimeout, "Helm chart timeout.")
@@ -75,11 +91,27 @@ func main() {
 	flag.DurationVar(&controllers.HardDeleteCheckInterval, "hard-delete-check-interval", controllers.HardDeleteCheckInterval, "Hard delete retry interval.")
 	flag.DurationVar(&controllers.HardDeleteTimeout, "hard-delete-timeout", controllers.HardDeleteTimeout, "Hard delete timeout.")
 	flag.DurationVar(&controllers.DeleteRequestTimeout, "delete-request-timeout", controllers.DeleteRequestTimeout, "Delete request timeout in hard delete.")
+	flag.IntVar(&controllers.DeletionWorkers, "deletion-workers", controllers.DeletionWorkers, "Maximum number of namespaces to send delete requests for in parallel in hard delete.")
+	flag.IntVar(&controllers.DeletionRetryAttempts, "deletion-retry-attempts", controllers.DeletionRetryAttempts, "Number of delete request attempts per namespace in hard delete.")
+	flag.DurationVar(&controllers.DeletionRetryBackoff, "deletion-retry-backoff", controllers.DeletionRetryBackoff, "Initial backoff between delete request attempts in hard delete, doubled after each attempt.")
+	flag.DurationVar(&controllers.SoftDeleteStuckThreshold, "soft-delete-stuck-threshold", controllers.SoftDeleteStuckThreshold, "Time in deletion after which soft delete removes finalizers from a Service Instance or Service Binding.")
+	flag.DurationVar(&controllers.SoftDeleteCheckInterval, "soft-delete-check-interval", controllers.SoftDeleteCheckInterval, "Interval of checking Service Instances and Service Bindings still being deleted in soft delete.")
+	flag.BoolVar(&controllers.SoftDeleteKeepBindingSecrets, "soft-delete-keep-binding-secrets", controllers.SoftDeleteKeepBindingSecrets, "Keep Secrets of Service Bindings orphaned in soft delete instead of deleting them.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Enable the validating webhooks for BtpOperator, ServiceInstance, and ServiceBinding CRs.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhook.CertDir, "webhook-cert-dir", webhook.CertDir, "Directory where the webhook serving certificate is written to.")
+	flag.StringVar(&webhook.Namespace, "webhook-namespace", webhook.Namespace, "Namespace of the webhook Service and the webhook certificates Secret.")
+	flag.StringVar(&webhook.ServiceName, "webhook-service-name", webhook.ServiceName, "Name of the webhook Service.")
+	flag.StringVar(&webhook.ValidatingWebhookConfigName, "validating-webhook-config-name", webhook.ValidatingWebhookConfigName, "Name of the ValidatingWebhookConfiguration to inject the CA bundle into.")
+	flag.DurationVar(&webhook.CertificateCheckInterval, "webhook-cert-check-interval", webhook.CertificateCheckInterval, "Interval of renewing the webhook certificates which expire soon and reloading the serving certificate.")
+	flag.StringVar(&webhook.CertSecretName, "webhook-cert-secret-name", webhook.CertSecretName, "Name of the Secret with the webhook certificates.")
+	flag.StringVar(&webhook.ManagerServiceAccount, "manager-service-account", webhook.ManagerServiceAccount, "Username of the btp-manager service account allowed to remove the BtpOperator finalizer during deprovisioning.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
+	controllers.ModuleFS = moduleFS
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
@@ -91,6 +123,10 @@ func main() {
 		Metrics:                server.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		NewCache:               controllers.CacheCreator,
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    webhookPort,
+			CertDir: webhook.CertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -99,13 +135,32 @@ func main() {
 
 	signalContext := ctrl.SetupSignalHandler()
 	metrics := btpmanagermetrics.NewMetrics()
-	cleanupReconciler := controllers.NewInstanceBindingControllerManager(signalContext, mgr.GetClient(), mgr.GetScheme(), restCfg)
+	cleanupReconciler := controllers.NewInstanceBindingControllerManager(signalContext, mgr)
 	reconciler := controllers.NewBtpOperatorReconciler(mgr.GetClient(), scheme, cleanupReconciler, metrics)
 
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "BtpOperator")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		certificateProvisioner := webhook.NewCertificateProvisioner(mgr.GetAPIReader(), mgr.GetClient())
+		if err := certificateProvisioner.Provision(ctrl.LoggerInto(signalContext, setupLog)); err != nil {
+			setupLog.Error(err, "unable to provision webhook certificates")
+			os.Exit(1)
+		}
+		if err := certificateProvisioner.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificates renewal")
+			os.Exit(1)
+		}
+		if err := webhook.NewBtpOperatorValidator(mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BtpOperator")
+			os.Exit(1)
+		}
+		if err := webhook.NewDeprovisioningGuard(mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DeprovisioningGuard")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
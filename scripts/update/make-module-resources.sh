@@ -18,6 +18,7 @@ readonly EXCLUDED_RESOURCES_PATH="${EXISTING_RESOURCES_PATH}/excluded"
 readonly HELM_OUTPUT_PATH="rendered"
 readonly NEW_RESOURCES_PATH="rendered/sap-btp-operator/templates"
 readonly RBAC_FILE_PATH="../../controllers/btpoperator_controller.go"
+readonly CHECKSUMS_FILE="checksums.json"
 
 TAG=$1
 helm template ${TAG} ${CHART_PATH} --output-dir ${HELM_OUTPUT_PATH} --values ${CHART_OVERRIDES_PATH} --namespace "kyma-system"
@@ -76,6 +77,9 @@ runActionForEachYaml() {
   if [ "$(ls -A $directory)" ]; then    
     for combinedYaml in $directory/*
     do
+        if [[ "$(basename $combinedYaml)" == "$CHECKSUMS_FILE" ]]; then
+          continue
+        fi
         mkdir 'temp' && cd 'temp'
         yq -s '"file_" + $index' "../$combinedYaml"
         for singleYaml in *
@@ -119,6 +123,11 @@ updateRbac() {
     (cd ../../; make manifests)
 }
 
+updateChecksums() {
+    # signed with the key from MANIFESTS_KEY_FILE if it's set
+    (cd ../../; make module-resources-checksums)
+}
+
 incoming_resources=()
 touch to-exclude.yml
 filterProhibitedFiles ${NEW_RESOURCES_PATH}
@@ -139,5 +148,7 @@ mv $NEW_RESOURCES_PATH/* $EXISTING_RESOURCES_APPLY_PATH
 mv to-delete.yml $EXISTING_RESOURCES_DELETE_PATH
 mv to-exclude.yml $EXCLUDED_RESOURCES_PATH
 
+updateChecksums
+
 rm -r $HELM_OUTPUT_PATH
 